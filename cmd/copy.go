@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pbs/gorson/internal/gorson/cli"
+	"github.com/pbs/gorson/pkg/gorson"
+	"github.com/spf13/cobra"
+)
+
+var fromProfile string
+var fromRegion string
+var toProfile string
+var toRegion string
+var include []string
+var exclude []string
+
+// newClientFor returns a gorson client using an AWS profile and region, or the defaults if they're empty
+func newClientFor(ctx context.Context, profile string, region string) *gorson.Client {
+	client, err := gorson.NewForProfile(ctx, profile, region)
+	if err != nil {
+		fail(err)
+	}
+	return protectClient(client)
+}
+
+func copyParameters(ctx context.Context, src string, dst string) {
+	srcClient := newClientFor(ctx, fromProfile, fromRegion)
+	dstClient := newClientFor(ctx, toProfile, toRegion)
+	opts := gorson.CopyOptions{
+		PutOptions: writeOptions(),
+		Delete:     delete,
+		Approve:    approveDelete,
+		KeyID:      kmsKeyID,
+		Include:    include,
+		Exclude:    exclude,
+	}
+
+	if dryRun {
+		pl, err := srcClient.PlanCopy(ctx, src, dstClient, dst, opts)
+		if err != nil {
+			fail(err)
+		}
+		printPlan(pl)
+		return
+	}
+
+	takeSnapshot(ctx, dstClient, dst)
+	result, err := srcClient.Copy(ctx, src, dstClient, dst, opts)
+	if result != nil {
+		printReport(&result.PutResult, err != nil)
+	}
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("wrote %d parameters, skipped %d unchanged parameters, deleted %d parameters, tagged %d parameters\n",
+		len(result.Written), len(result.Skipped), len(result.Deleted), len(result.Tagged))
+}
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "copy /source/parameter/store/path /destination/parameter/store/path",
+		Short: "copy parameters from one parameter store path to another, keeping their types, descriptions, KMS keys and tags",
+		Run: func(cmd *cobra.Command, args []string) {
+			samePath := cli.NormalizePath(args[0]) == cli.NormalizePath(args[1])
+			if samePath && fromProfile == toProfile && fromRegion == toRegion {
+				fail(errors.New("the source and destination are the same"))
+			}
+			copyParameters(cmd.Context(), args[0], args[1])
+		},
+		Args: cobra.ExactArgs(2),
+	}
+	cmd.Flags().StringVar(&fromProfile, "from-profile", "", "the AWS profile to read the source path with")
+	cmd.Flags().StringVar(&fromRegion, "from-region", "", "the AWS region to read the source path from")
+	cmd.Flags().StringVar(&toProfile, "to-profile", "", "the AWS profile to write the destination path with")
+	cmd.Flags().StringVar(&toRegion, "to-region", "", "the AWS region to write the destination path to")
+	cmd.Flags().StringSliceVar(&include, "include", nil, "only copy keys matching these glob patterns, like db_*")
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "don't copy keys matching these glob patterns, like *_password")
+	cmd.Flags().StringVar(&kmsKeyID, "kms-key-id", "", "re-encrypt SecureString parameters with this KMS key, instead of the key they're encrypted with at the source")
+	cmd.Flags().BoolVarP(&delete, "delete", "d", false, "deletes parameters at the destination that are not copied from the source")
+	addWriteFlags(cmd)
+	rootCmd.AddCommand(cmd)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/pbs/gorson/internal/gorson/cli"
+	"github.com/pbs/gorson/pkg/gorson"
+	"github.com/spf13/cobra"
+)
+
+var showValues bool
+var outputFormat string
+
+func diffParameters(ctx context.Context, path string, parameters map[string]string) {
+	changes, err := newClient(ctx).Diff(ctx, path, parameters)
+	if err != nil {
+		fail(err)
+	}
+	if outputFormat == "json" {
+		output, err := cli.DiffJSON(changes, showValues)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Print(output)
+	} else if outputFormat == "text" {
+		fmt.Println(cli.DiffText(changes, showValues))
+	} else {
+		fail(errors.New("No proper output requested. (text, json allowed)"))
+	}
+	if gorson.HasDrift(changes) {
+		os.Exit(exitDrift)
+	}
+}
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "diff /a/parameter/store/path --file /path/to/a/file",
+		Short: "compare a file against a parameter store path, exiting with 2 if they differ",
+		Run: func(cmd *cobra.Command, args []string) {
+			path := args[0]
+			parameters, err := gorson.ReadFile(filename, inputFormat)
+			if err != nil {
+				fail(err)
+			}
+			diffParameters(cmd.Context(), path, parameters)
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().StringVarP(&filename, "file", "f", "", "json, yaml or env file to read key/value pairs from")
+	cmd.Flags().StringVar(&inputFormat, "input-format", "", "the format of --file, instead of guessing it from the extension. (json, yaml, env allowed)")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "the format of gorson diff output. (text, json allowed)")
+	cmd.Flags().BoolVar(&showValues, "show-values", false, "reveal parameter values instead of masking them")
+	err := cmd.MarkFlagRequired("file")
+	if err != nil {
+		log.Fatal(err)
+	}
+	rootCmd.AddCommand(cmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pbs/gorson/pkg/gorson"
+)
+
+// exit codes, so scripts can tell classes of failure apart
+const (
+	exitError        = 1
+	exitDrift        = 2
+	exitInvalidFile  = 3
+	exitInvalidKey   = 4
+	exitNotFound     = 5
+	exitAccessDenied = 6
+	exitThrottled    = 7
+	exitTimeout      = 8
+	// exitCanceled follows the shell convention for a process stopped by Ctrl-C
+	exitCanceled = 130
+)
+
+// exitCode returns the exit code for the class of an error
+func exitCode(err error) int {
+	var invalidKey *gorson.InvalidKeyError
+	switch {
+	case errors.Is(err, gorson.ErrInvalidFile):
+		return exitInvalidFile
+	case errors.As(err, &invalidKey):
+		return exitInvalidKey
+	case errors.Is(err, gorson.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, gorson.ErrAccessDenied):
+		return exitAccessDenied
+	case errors.Is(err, gorson.ErrThrottled):
+		return exitThrottled
+	case errors.Is(err, gorson.ErrTimeout):
+		return exitTimeout
+	case errors.Is(err, context.Canceled):
+		return exitCanceled
+	}
+	return exitError
+}
+
+// fail prints an error and exits with the exit code for its class
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "gorson: %s\n", err)
+	os.Exit(exitCode(err))
+}
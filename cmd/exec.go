@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/pbs/gorson/internal/gorson/cli"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+)
+
+var noOverride bool
+
+// run starts a command with the given environment, forwards signals to it,
+// and returns its exit code once it finishes
+func run(command []string, environ []string) int {
+	child := exec.Command(command[0], command[1:]...)
+	child.Env = environ
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	// we start listening before the child starts, so no signal slips through
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	// in the foreground of a terminal, Ctrl-C and Ctrl-\ already reach the child, as it's in our process group,
+	// so they're only caught to keep gorson alive until the child exits. Forwarding them would deliver them twice.
+	keyboard := inForeground()
+
+	if err := child.Start(); err != nil {
+		fail(err)
+	}
+	go func() {
+		for sig := range signals {
+			if keyboard && (sig == os.Interrupt || sig == syscall.SIGQUIT) {
+				continue
+			}
+			_ = child.Process.Signal(sig)
+		}
+	}()
+
+	err := child.Wait()
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		fail(err)
+	}
+	// a child killed by a signal has no exit code: follow the shell convention of 128 + signal
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
+
+// inForeground reports whether gorson runs in the foreground process group of its controlling terminal,
+// where the terminal sends Ctrl-C and Ctrl-\ to every process in the group
+func inForeground() bool {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	defer tty.Close()
+	pgrp, err := unix.IoctlGetInt(int(tty.Fd()), unix.TIOCGPGRP)
+	return err == nil && pgrp == unix.Getpgrp()
+}
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "exec [/a/parameter/store/path ./example.json ...] [--file ./example.json] -- command [args...]",
+		Short: "run a command with parameters from parameter store paths or files set as environment variables. Several sources are merged, later ones winning",
+		Run: func(cmd *cobra.Command, args []string) {
+			dash := cmd.ArgsLenAtDash()
+			if dash < 0 || dash == len(args) {
+				fail(errors.New("a command to run is required after --"))
+			}
+			sources, command := parseSources(args[:dash], false), args[dash:]
+
+			if filename != "" {
+				if len(sources) != 0 {
+					fail(errors.New("use either parameter store paths and files before --, or --file, not both"))
+				}
+				sources = []source{{name: filename, file: true}}
+			}
+			if len(sources) == 0 {
+				fail(errors.New("a parameter store path or file is required"))
+			}
+			parameters := readSources(cmd.Context(), sources, !noResolve, false)
+
+			environ := cli.MergeEnviron(os.Environ(), parameters, !noOverride)
+			os.Exit(run(command, environ))
+		},
+	}
+	cmd.Flags().StringVarP(&filename, "file", "f", "", "json, yaml or env file to read key/value pairs from instead of parameter store")
+	cmd.Flags().StringVar(&inputFormat, "input-format", "", "the format of the files, instead of guessing it from their extension. (json, yaml, env allowed)")
+	cmd.Flags().BoolVar(&noOverride, "no-override", false, "keep existing environment variables instead of overriding them with parameters")
+	addSourceFlags(cmd)
+	rootCmd.AddCommand(cmd)
+}
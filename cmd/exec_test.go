@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestMain runs run as a stand-in for gorson exec when the test binary re-executes itself, so a test can signal it
+func TestMain(m *testing.M) {
+	if os.Getenv("GORSON_EXEC_HELPER") == "1" {
+		os.Exit(run([]string{"sh", "-c", `trap 'echo interrupted; exit 3' INT; echo ready; while :; do sleep 0.1; done`}, os.Environ()))
+	}
+	os.Exit(m.Run())
+}
+
+func TestRunForwardsSignalsWithoutTerminal(t *testing.T) {
+	helper := exec.Command(os.Args[0], "-test.run=^$")
+	helper.Env = append(os.Environ(), "GORSON_EXEC_HELPER=1")
+	// a new session has no controlling terminal, like gorson under a supervisor
+	helper.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	stdout, err := helper.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := helper.Start(); err != nil {
+		t.Fatal(err)
+	}
+	lines := bufio.NewScanner(stdout)
+	if !lines.Scan() || lines.Text() != "ready" {
+		t.Fatalf("expected the command to start, got %q", lines.Text())
+	}
+
+	if err := helper.Process.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		output := []string{}
+		for lines.Scan() {
+			output = append(output, lines.Text())
+		}
+		if len(output) != 1 || output[0] != "interrupted" {
+			t.Errorf("expected the command to get SIGINT once, got %v", output)
+		}
+		done <- helper.Wait()
+	}()
+	select {
+	case err := <-done:
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+			t.Errorf("expected gorson to exit with the command's exit code 3, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		_ = helper.Process.Kill()
+		t.Fatal("expected SIGINT to be forwarded to the command, but it kept running")
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pbs/gorson/internal/gorson/cli"
+	"github.com/pbs/gorson/pkg/gorson"
+	"github.com/spf13/cobra"
+)
+
+var k8sName string
+var k8sNamespace string
+var k8sLabels map[string]string
+
+// addFormatFlags adds --format, and the settings only some formats use, to a command that outputs parameters
+func addFormatFlags(cmd *cobra.Command, p *string, defaultFormat string) {
+	usage := fmt.Sprintf("the format of gorson %s output. (%s allowed, or help to describe each)", cmd.Name(), strings.Join(gorson.Formats(), ", "))
+	cmd.Flags().StringVarP(p, "format", "f", defaultFormat, usage)
+	cmd.Flags().StringVar(&k8sName, "k8s-name", "", "the name of a k8s-secret or k8s-configmap. (default named after the path or file, like app-prod for /app/prod/)")
+	cmd.Flags().StringVar(&k8sNamespace, "k8s-namespace", "", "the namespace of a k8s-secret or k8s-configmap")
+	cmd.Flags().StringToStringVar(&k8sLabels, "k8s-label", nil, "a label for a k8s-secret or k8s-configmap, like app=web. Repeatable")
+}
+
+// printFormatHelp describes every format when --format help is given, and reports whether it did
+func printFormatHelp(name string) bool {
+	if name != "help" {
+		return false
+	}
+	fmt.Println(gorson.FormatHelp())
+	return true
+}
+
+// printFormatted outputs parameters in a registered format. Manifests without --k8s-name are named after source.
+func printFormatted(pms map[string]string, name string, source string) {
+	opts := gorson.FormatOptions{Manifest: gorson.ManifestOptions{Name: k8sName, Namespace: k8sNamespace, Labels: k8sLabels}}
+	if opts.Manifest.Name == "" {
+		opts.Manifest.Name = cli.ManifestName(source)
+	}
+	output, err := gorson.FormatWith(pms, name, opts)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Println(output)
+}
@@ -1,50 +1,132 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"github.com/pbs/gorson/internal/gorson/env"
-	"log"
 
-	"github.com/pbs/gorson/internal/gorson/io"
-	"github.com/pbs/gorson/internal/gorson/json"
-	"github.com/pbs/gorson/internal/gorson/util"
+	"github.com/pbs/gorson/internal/gorson/cli"
+	"github.com/pbs/gorson/pkg/gorson"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
 var format string
+var recursive bool
+var keyStyle string
+var extended bool
+var kmsKeyIDs bool
+var resolveAll bool
 
-func get(path string) {
-	p := util.NewParameterStorePath(path)
-	pms := io.ReadFromParameterStore(*p, nil)
-	if format == "yaml" || format == "yml" {
-		serialized, err := yaml.Marshal(pms)
-		if err != nil {
-			log.Fatal(err)
+func get(ctx context.Context, args []string) {
+	if printFormatHelp(format) {
+		return
+	}
+	sources := parseSources(args, false)
+	if (extended || kmsKeyIDs) && (len(sources) != 1 || sources[0].file) {
+		fail(errors.New("--extended and --kms-key-ids read a single parameter store path"))
+	}
+	if extended {
+		getExtended(ctx, sources[0].name)
+		return
+	}
+	if kmsKeyIDs {
+		getKeyIDs(ctx, sources[0].name)
+		return
+	}
+	pms := readSources(ctx, sources, !noResolve && (resolveAll || !keepsReferences(format)), true)
+	path := sourceName(sources[len(sources)-1])
+	if recursive {
+		printRecursive(pms, path)
+		return
+	}
+	printParameters(pms, path)
+}
+
+// keepsReferences reports whether get leaves references unresolved in a format by default, so its output
+// can be put back unchanged. json, yaml and env hold parameters to be put, while scripts and manifests are used as they are.
+func keepsReferences(name string) bool {
+	switch name {
+	case "json", "yaml", "yml", "env":
+		return true
+	}
+	return false
+}
+
+// getExtended outputs parameters with their types and descriptions, in the schema put reads
+func getExtended(ctx context.Context, path string) {
+	if recursive && keyStyle != "path" {
+		fail(errors.New("--extended only supports the path key style"))
+	}
+	pms, err := newClient(ctx).GetParameters(ctx, path, gorson.GetOptions{Recursive: recursive})
+	if err != nil {
+		fail(err)
+	}
+	requireParameters(path, len(pms))
+	output, err := gorson.FormatParameters(pms, format)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Println(output)
+}
+
+// getKeyIDs outputs the KMS key each SecureString parameter is encrypted with, instead of its value
+func getKeyIDs(ctx context.Context, path string) {
+	pms, err := newClient(ctx).GetParameters(ctx, path, gorson.GetOptions{Recursive: recursive})
+	if err != nil {
+		fail(err)
+	}
+	requireParameters(path, len(pms))
+	keyIDs := make(map[string]string)
+	for k, parameter := range pms {
+		if parameter.Secure() {
+			keyIDs[k] = parameter.KeyID
 		}
-		fmt.Println(string(serialized))
+	}
+	printParameters(keyIDs, path)
+}
 
-	} else if format == "env" {
-		envs := env.Marshal(pms)
-		fmt.Println(envs)
-	} else if format == "json" {
-		marshalled := json.Marshal(pms)
-		fmt.Println(marshalled)
-	} else {
-		log.Fatal("No proper format requested. (yaml, env, json allowed)")
+// printParameters outputs flat key/value pairs read from a path in the requested format
+func printParameters(pms map[string]string, path string) {
+	printFormatted(pms, format, path)
+}
+
+// printRecursive outputs parameters read from below a path, with keys formed according to keyStyle
+func printRecursive(pms map[string]string, path string) {
+	if keyStyle == "env" {
+		envKeys, err := cli.EnvKeys(pms)
+		if err != nil {
+			fail(err)
+		}
+		pms = envKeys
+	} else if keyStyle == "nested" {
+		output, err := cli.FormatNested(pms, format)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println(output)
+		return
+	} else if keyStyle != "path" {
+		fail(errors.New("No proper key style requested. (path, nested, env allowed)"))
 	}
+	printParameters(pms, path)
 }
 
 func init() {
 	cmd := &cobra.Command{
-		Use:   "get /a/parameter/store/path",
-		Short: "Get parameters from a parameter store path",
+		Use:   "get /a/parameter/store/path [more paths or files...]",
+		Short: "Get parameters from a parameter store path. Several paths or json, yaml or env files are merged, later ones winning",
 		Run: func(cmd *cobra.Command, args []string) {
-			path := args[0]
-			get(path)
+			get(cmd.Context(), args)
 		},
-		Args: cobra.ExactArgs(1),
+		Args: cobra.MinimumNArgs(1),
 	}
-	cmd.Flags().StringVarP(&format, "format", "f", "json", "the format of gorson get output.")
+	addFormatFlags(cmd, &format, "json")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "include parameters nested below the path")
+	cmd.Flags().StringVar(&keyStyle, "key-style", "path", "how --recursive forms keys from nested names. (path: db/host, nested: {db: {host}}, env: DB_HOST)")
+	cmd.Flags().BoolVar(&extended, "extended", false, "include each parameter's type, description and KMS key, as {key: {value, type, description, kms_key_id}}. (yaml, json allowed)")
+	cmd.Flags().BoolVar(&kmsKeyIDs, "kms-key-ids", false, "output the KMS key each SecureString parameter is encrypted with, instead of its value")
+	cmd.Flags().StringVar(&inputFormat, "input-format", "", "the format of the files, instead of guessing it from their extension. (json, yaml, env allowed)")
+	cmd.Flags().BoolVar(&resolveAll, "resolve", false, "resolve references in json, yaml and env output too, which otherwise keeps them so it can be put back unchanged")
+	addSourceFlags(cmd)
 	rootCmd.AddCommand(cmd)
 }
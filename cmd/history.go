@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pbs/gorson/internal/gorson/cli"
+	"github.com/spf13/cobra"
+)
+
+func printHistory(ctx context.Context, path string) {
+	versions, err := newClient(ctx).History(ctx, path)
+	if err != nil {
+		fail(err)
+	}
+	if outputFormat == "json" {
+		output, err := cli.HistoryJSON(versions, path, showValues)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Print(output)
+	} else if outputFormat == "text" {
+		fmt.Println(cli.HistoryText(versions, path, showValues))
+	} else {
+		fail(errors.New("No proper output requested. (text, json allowed)"))
+	}
+}
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "history /a/parameter/store/path",
+		Short: "show every version of each parameter at a path, with when and by whom it was written",
+		Run: func(cmd *cobra.Command, args []string) {
+			printHistory(cmd.Context(), args[0])
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "the format of gorson history output. (text, json allowed)")
+	cmd.Flags().BoolVar(&showValues, "show-values", false, "reveal parameter values instead of masking them")
+	rootCmd.AddCommand(cmd)
+}
@@ -1,25 +1,29 @@
 package cmd
 
 import (
-	"fmt"
-
-	"github.com/pbs/gorson/internal/gorson/bash"
-	"github.com/pbs/gorson/internal/gorson/io"
 	"github.com/spf13/cobra"
 )
 
+var loadFormat string
+
 func init() {
 	cmd := &cobra.Command{
-		Use: "load ./example.json",
-		Short: `reads a json file of key/value pairs, outputs a bash script to export them
-			to set in shell, source <(gorson load ./example.json)`,
+		Use: "load ./example.json [more files or ssm:/parameter/store/paths...]",
+		Short: `reads a json, yaml or env file of key/value pairs, outputs a shell script to export them
+			to set in shell, source <(gorson load ./example.json). Several sources are merged, later ones winning`,
 		Run: func(cmd *cobra.Command, args []string) {
-			filename := args[0]
-			pms := io.ReadJSONFile(filename)
-			output := bash.ParamsToShell(pms)
-			fmt.Println(output)
+			if printFormatHelp(loadFormat) {
+				return
+			}
+			sources := parseSources(args, true)
+			pms := readSources(cmd.Context(), sources, !noResolve, false)
+			printFormatted(pms, loadFormat, sourceName(sources[len(sources)-1]))
 		},
-		Args: cobra.ExactArgs(1),
+		Args: cobra.MinimumNArgs(1),
 	}
+	addFormatFlags(cmd, &loadFormat, "bash")
+	cmd.Flags().StringVar(&loadFormat, "shell", "bash", "the shell to write the script for, the same as --format")
+	cmd.Flags().StringVar(&inputFormat, "input-format", "", "the format of the files, instead of guessing it from their extension. (json, yaml, env allowed)")
+	addSourceFlags(cmd)
 	rootCmd.AddCommand(cmd)
 }
@@ -1,36 +1,138 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
 	"strconv"
 	"time"
 
-	"github.com/pbs/gorson/internal/gorson/io"
-	"github.com/pbs/gorson/internal/gorson/util"
+	"github.com/pbs/gorson/internal/gorson/cli"
+	"github.com/pbs/gorson/pkg/gorson"
 	"github.com/spf13/cobra"
 )
 
 var filename string
+var inputFormat string
 var timeout string
 var delete bool
+var dryRun bool
+var concurrency int
+var writeRate float64
+var retryBudget int
+var reportFile string
+var kmsKeyID string
 
-func put(path string, parameters map[string]string, timeout string, delete bool, autoApprove bool) {
-	p := util.NewParameterStorePath(path)
+// addWriteFlags adds the flags shared by every command that writes to a path: --dry-run and how its plan is printed,
+// how long, how fast and how many at once to write, and where to report the outcome
+func addWriteFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the planned writes and deletes without writing anything")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "the format of the --dry-run plan. (text, json allowed)")
+	cmd.Flags().BoolVar(&showValues, "show-values", false, "reveal parameter values in the plan and the delete prompt instead of masking them")
+	cmd.Flags().StringVarP(&timeout, "timeout", "t", "1", "timeout in minutes for writing")
+	cmd.Flags().IntVar(&concurrency, "concurrency", gorson.DefaultConcurrency, "how many parameters to write at once")
+	cmd.Flags().Float64Var(&writeRate, "rate", 0, "the most write requests per second, retries included. 0 means no limit")
+	cmd.Flags().IntVar(&retryBudget, "retry-budget", gorson.DefaultRetryBudget, "how many throttled writes may be retried in total before giving up")
+	cmd.Flags().StringVar(&reportFile, "report", "", "json file to write the outcome of every key to")
+}
+
+// writeOptions returns the options the flags added by addWriteFlags ask for
+func writeOptions() gorson.PutOptions {
 	timeoutInt, err := strconv.ParseInt(timeout, 0, 64)
-	timeoutDuration := time.Duration(timeoutInt) * time.Minute
 	if err != nil {
-		log.Fatal(err)
+		fail(err)
+	}
+	return gorson.PutOptions{
+		Timeout:     time.Duration(timeoutInt) * time.Minute,
+		Concurrency: concurrency,
+		Rate:        writeRate,
+		RetryBudget: retryBudget,
 	}
-	err = io.WriteToParameterStore(parameters, *p, timeoutDuration, nil)
+}
+
+// planPut prints what put would do without writing or deleting anything
+func planPut(ctx context.Context, path string, parameters map[string]gorson.Parameter, delete bool) {
+	pl, err := newClient(ctx).PlanParameters(ctx, path, parameters, delete)
 	if err != nil {
-		log.Fatal(err)
+		fail(err)
+	}
+	printPlan(pl)
+}
+
+// printPlan prints a plan in the requested output format
+func printPlan(pl gorson.Plan) {
+	if outputFormat == "json" {
+		output, err := pl.JSON()
+		if err != nil {
+			fail(err)
+		}
+		fmt.Print(output)
+	} else if outputFormat == "text" {
+		fmt.Println(pl.TextWith(showValues))
+	} else {
+		fail(errors.New("No proper output requested. (text, json allowed)"))
+	}
+}
+
+// approveDelete shows the user the plan and asks which parameters to delete, unless prompts are auto-approved.
+// Without a terminal to ask on, it refuses rather than reading an answer from piped input.
+func approveDelete(pl gorson.Plan) ([]string, error) {
+	if autoApprove {
+		return pl.Keys(gorson.Delete), nil
 	}
+	if !cli.IsTerminal(os.Stdin) {
+		return nil, fmt.Errorf("%w: can't confirm deleting parameters from %s, pass --auto-approve to delete them without asking", gorson.ErrNotTerminal, pl.Path)
+	}
+	return cli.ApproveDeletes(pl, showValues, os.Stdin, os.Stdout)
+}
+
+// printReport prints the outcome of every write, to stderr if any failed, and saves it as json when --report is given
+func printReport(result *gorson.PutResult, failed bool) {
+	if result == nil {
+		return
+	}
+	if reportFile != "" {
+		output, err := result.Report.JSON()
+		if err != nil {
+			fail(err)
+		}
+		if err := os.WriteFile(reportFile, []byte(output), 0600); err != nil {
+			fail(err)
+		}
+	}
+	if len(result.Report.Entries) == len(result.Skipped) {
+		return
+	}
+	if failed {
+		fmt.Fprintln(os.Stderr, result.Report.Text())
+	} else {
+		fmt.Println(result.Report.Text())
+	}
+}
+
+func put(ctx context.Context, path string, parameters map[string]gorson.Parameter, delete bool) {
+	opts := writeOptions()
+	client := newClient(ctx)
+	takeSnapshot(ctx, client, path)
 	if delete {
-		_, err = io.DeleteDeltaFromParameterStore(parameters, *p, autoApprove, nil)
+		result, err := client.SyncParameters(ctx, path, parameters, gorson.SyncOptions{PutOptions: opts, Approve: approveDelete})
+		if result != nil {
+			printReport(&result.PutResult, err != nil)
+		}
 		if err != nil {
-			log.Fatal(err)
+			fail(err)
 		}
+		fmt.Printf("wrote %d parameters, skipped %d unchanged parameters, deleted %d parameters\n", len(result.Written), len(result.Skipped), len(result.Deleted))
+		return
+	}
+	result, err := client.PutParameters(ctx, path, parameters, opts)
+	printReport(result, err != nil)
+	if err != nil {
+		fail(err)
 	}
+	fmt.Printf("wrote %d parameters, skipped %d unchanged parameters\n", len(result.Written), len(result.Skipped))
 }
 
 func init() {
@@ -39,14 +141,24 @@ func init() {
 		Short: "write parameters to a parameter store path",
 		Run: func(cmd *cobra.Command, args []string) {
 			path := args[0]
-			parameters := io.ReadJSONFile(filename)
-			put(path, parameters, timeout, delete, autoApprove)
+			parameters, err := gorson.ReadParameterFile(filename, inputFormat)
+			if err != nil {
+				fail(err)
+			}
+			parameters = gorson.WithKeyID(parameters, kmsKeyID)
+			if dryRun {
+				planPut(cmd.Context(), path, parameters, delete)
+				return
+			}
+			put(cmd.Context(), path, parameters, delete)
 		},
 		Args: cobra.ExactArgs(1),
 	}
-	cmd.Flags().StringVarP(&filename, "file", "f", "", "json file to read key/value pairs from")
-	cmd.Flags().StringVarP(&timeout, "timeout", "t", "1", "timeout in minutes for put")
-	cmd.Flags().BoolVarP(&delete, "delete", "d", false, "deletes parameters that are not present in the json file")
+	cmd.Flags().StringVarP(&filename, "file", "f", "", "json, yaml or env file to read key/value pairs from")
+	cmd.Flags().StringVar(&inputFormat, "input-format", "", "the format of --file, instead of guessing it from the extension. (json, yaml, env allowed)")
+	cmd.Flags().BoolVarP(&delete, "delete", "d", false, "deletes parameters that are not present in the file")
+	cmd.Flags().StringVar(&kmsKeyID, "kms-key-id", "", "the KMS key to encrypt SecureString parameters with, unless the file names one. (default alias/aws/ssm)")
+	addWriteFlags(cmd)
 	err := cmd.MarkFlagRequired("file")
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pbs/gorson/pkg/gorson"
+	"github.com/spf13/cobra"
+)
+
+var templateFile string
+var renderPath string
+var renderOut string
+
+// renderTemplate fills a template with parameters from a path, and writes it to renderOut, or stdout if it's empty
+func renderTemplate(ctx context.Context) {
+	text, err := os.ReadFile(templateFile)
+	if err != nil {
+		fail(err)
+	}
+	pms, err := newClient(ctx).Get(ctx, renderPath, gorson.GetOptions{Recursive: recursive, Resolve: !noResolve})
+	if err != nil {
+		fail(err)
+	}
+	// nothing is written unless the whole template renders
+	output, err := gorson.Render(filepath.Base(templateFile), string(text), pms)
+	if err != nil {
+		fail(err)
+	}
+	if renderOut == "" {
+		fmt.Print(output)
+		return
+	}
+	if err := writePrivate(renderOut, output); err != nil {
+		fail(err)
+	}
+}
+
+// writePrivate writes a file with mode 0600, even when it already exists with a wider mode. The content goes to a
+// temp file in the same directory first, which os.CreateTemp makes 0600, and is then renamed over the file.
+func writePrivate(filename string, content string) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "render --template app.conf.tmpl --path /a/parameter/store/path [--out app.conf]",
+		Short: "fill a go text/template with parameters from a parameter store path",
+		Run: func(cmd *cobra.Command, args []string) {
+			renderTemplate(cmd.Context())
+		},
+		Args: cobra.NoArgs,
+	}
+	cmd.Flags().StringVar(&templateFile, "template", "", "the go text/template file to render")
+	cmd.Flags().StringVar(&renderPath, "path", "", "the parameter store path to read parameters from")
+	cmd.Flags().StringVar(&renderOut, "out", "", "the file to write, with mode 0600. Writes to stdout if empty")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "include parameters nested below the path, as keys like db/host")
+	addResolveFlag(cmd)
+	_ = cmd.MarkFlagRequired("template")
+	_ = cmd.MarkFlagRequired("path")
+	rootCmd.AddCommand(cmd)
+}
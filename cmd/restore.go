@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pbs/gorson/pkg/gorson"
+	"github.com/spf13/cobra"
+)
+
+// snapshotPaths returns the snapshot directory and key file, defaulting to the user config directory
+func snapshotPaths() (string, string, error) {
+	dir, key := snapshotDir, snapshotKey
+	if dir != "" && key != "" {
+		return dir, key, nil
+	}
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return "", "", fmt.Errorf("can't find a directory for snapshots, set --snapshot-dir and --snapshot-key: %w", err)
+	}
+	if dir == "" {
+		dir = filepath.Join(config, "gorson", "snapshots")
+	}
+	if key == "" {
+		key = filepath.Join(config, "gorson", "snapshot.key")
+	}
+	return dir, key, nil
+}
+
+// takeSnapshot saves the state of a path before it's written to or deleted from, unless --no-snapshot is set,
+// then removes all but the newest --snapshot-keep snapshots of it. A snapshot is a safety net rather than part of the
+// write, so when one can't be taken, like without ssm:DescribeParameters or a writable config directory,
+// it warns and lets the write go ahead.
+func takeSnapshot(ctx context.Context, client *gorson.Client, path string) {
+	if noSnapshot {
+		return
+	}
+	filename, err := saveSnapshot(ctx, client, path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: writing to %s without a snapshot (--no-snapshot skips this warning): %v\n", path, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "saved a snapshot of %s to %s\n", path, filename)
+	if snapshotKeep <= 0 {
+		return
+	}
+	if _, err := gorson.PruneSnapshots(filepath.Dir(filename), path, snapshotKeep); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: can't remove old snapshots of %s: %v\n", path, err)
+	}
+}
+
+func saveSnapshot(ctx context.Context, client *gorson.Client, path string) (string, error) {
+	dir, key, err := snapshotPaths()
+	if err != nil {
+		return "", err
+	}
+	s, err := client.Snapshot(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	return gorson.SaveSnapshot(s, dir, key)
+}
+
+func restore(ctx context.Context, filename string) {
+	_, key, err := snapshotPaths()
+	if err != nil {
+		fail(err)
+	}
+	if _, err := os.Stat(key); errors.Is(err, os.ErrNotExist) {
+		fail(fmt.Errorf("%w: no snapshot key at %s, set --snapshot-key to the key %s was saved with", gorson.ErrInvalidFile, key, filename))
+	}
+	s, err := gorson.LoadSnapshot(filename, key)
+	if err != nil {
+		fail(err)
+	}
+	client := newClient(ctx)
+	opts := gorson.RestoreOptions{
+		PutOptions: writeOptions(),
+		Delete:     delete,
+		Approve:    approveDelete,
+	}
+
+	if dryRun {
+		pl, err := client.PlanRestore(ctx, s, opts)
+		if err != nil {
+			fail(err)
+		}
+		printPlan(pl)
+		return
+	}
+
+	takeSnapshot(ctx, client, s.Path)
+	result, err := client.Restore(ctx, s, opts)
+	if result != nil {
+		printReport(&result.PutResult, err != nil)
+	}
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("restored %s as of %s: wrote %d parameters, skipped %d unchanged parameters, deleted %d parameters\n",
+		s.Path, s.Taken.Local().Format(time.RFC3339), len(result.Written), len(result.Skipped), len(result.Deleted))
+}
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "restore <snapshot file>",
+		Short: "write a path back to the state saved in a snapshot",
+		Long: `write every parameter in a snapshot back to its path, with the value, type, description and KMS key it had.
+gorson saves an encrypted snapshot of a path before put, copy, rollback or restore write to it, and prints
+where it saved it. Unlike rollback, restore recreates parameters deleted since the snapshot was taken.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			restore(cmd.Context(), args[0])
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().BoolVarP(&delete, "delete", "d", false, "deletes parameters that weren't in the snapshot, like ones created since")
+	addWriteFlags(cmd)
+	rootCmd.AddCommand(cmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/pbs/gorson/internal/gorson/cli"
+	"github.com/pbs/gorson/pkg/gorson"
+	"github.com/spf13/cobra"
+)
+
+var rollbackTo string
+
+func rollback(ctx context.Context, path string) {
+	client := newClient(ctx)
+	opts := gorson.RollbackOptions{
+		PutOptions: writeOptions(),
+		Delete:     delete,
+		Approve:    approveDelete,
+	}
+
+	if dryRun {
+		pl, err := client.PlanRollback(ctx, path, rollbackTo, opts)
+		if err != nil {
+			fail(err)
+		}
+		printPlan(pl)
+		return
+	}
+
+	takeSnapshot(ctx, client, path)
+	result, err := client.Rollback(ctx, path, rollbackTo, opts)
+	if result != nil {
+		printReport(&result.PutResult, err != nil)
+	}
+	if err != nil {
+		fail(err)
+	}
+	if len(result.Missing) > 0 && !delete {
+		p := cli.NormalizePath(path)
+		fmt.Fprintf(os.Stderr, "these parameters have no version at %s, and were left alone (--delete removes them):\n", rollbackTo)
+		for _, key := range result.Missing {
+			fmt.Fprintln(os.Stderr, p+key)
+		}
+	}
+	fmt.Printf("wrote %d parameters, skipped %d unchanged parameters, deleted %d parameters\n", len(result.Written), len(result.Skipped), len(result.Deleted))
+}
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "rollback /a/parameter/store/path --to <timestamp|label>",
+		Short: "write every parameter at a path back to its value at a point in its history",
+		Long: `write every parameter at a path back to the value, type, description and KMS key it had at a
+timestamp, like 2026-10-17T12:00:00Z or 2026-10-17 12:00 in local time, or at a version label.
+Parameter store deletes a parameter's history along with it, so deleted parameters can't be recreated; restore them from a snapshot instead.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			rollback(cmd.Context(), args[0])
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().StringVar(&rollbackTo, "to", "", "the timestamp or version label to roll back to")
+	cmd.Flags().BoolVarP(&delete, "delete", "d", false, "deletes parameters that have no version at the timestamp or label, like ones created since")
+	addWriteFlags(cmd)
+	err := cmd.MarkFlagRequired("to")
+	if err != nil {
+		log.Fatal(err)
+	}
+	rootCmd.AddCommand(cmd)
+}
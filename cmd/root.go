@@ -1,16 +1,30 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/fatih/color"
+	"github.com/pbs/gorson/internal/gorson/cli"
+	"github.com/pbs/gorson/pkg/gorson"
 	"github.com/spf13/cobra"
 )
 
 var (
-	noColor     bool
-	autoApprove bool
-	rootCmd     = &cobra.Command{
+	noColor      bool
+	autoApprove  bool
+	noSnapshot   bool
+	snapshotDir  string
+	snapshotKey  string
+	snapshotKeep int
+	configFile   string
+	protect      []string
+	settings     cli.Config
+	rootCmd      = &cobra.Command{
 		Use:   "gorson",
 		Short: "get/put parameters to/from AWS parameter store, load them as environment variables",
 	}
@@ -20,15 +34,50 @@ func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "deactivate color usage")
 	rootCmd.PersistentFlags().BoolVar(&autoApprove, "auto-approve", false, "automatically approve any prompt")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", cli.DefaultConfigFile, "yaml config file, read when it exists")
+	rootCmd.PersistentFlags().StringSliceVar(&protect, "protect", nil, "glob pattern of keys never to delete, like terraform/* or /app/*/db_password. Repeatable, and added to the config file's protected list")
+	rootCmd.PersistentFlags().BoolVar(&noSnapshot, "no-snapshot", false, "don't save a snapshot of a path before writing to or deleting from it")
+	rootCmd.PersistentFlags().StringVar(&snapshotDir, "snapshot-dir", "", "directory snapshots are saved to. (default <user config dir>/gorson/snapshots)")
+	rootCmd.PersistentFlags().StringVar(&snapshotKey, "snapshot-key", "", "file holding the key snapshots are encrypted with, created if missing. (default <user config dir>/gorson/snapshot.key)")
+	rootCmd.PersistentFlags().IntVar(&snapshotKeep, "snapshot-keep", 20, "how many snapshots of each path to keep, removing the oldest. 0 keeps them all")
 }
 
 func initConfig() {
 	color.NoColor = noColor // disables colorized output
+	c, err := cli.ReadConfig(configFile)
+	// the default config file is optional, but one given with --config has to exist
+	if errors.Is(err, os.ErrNotExist) && !rootCmd.PersistentFlags().Changed("config") {
+		return
+	}
+	if err != nil {
+		fail(err)
+	}
+	settings = c
+}
+
+// protectClient keeps the config file's and --protect's patterns from being deleted by a client
+func protectClient(client *gorson.Client) *gorson.Client {
+	if err := client.Protect(append(settings.Protected, protect...)...); err != nil {
+		fail(err)
+	}
+	return client
+}
+
+// newClient returns a gorson client using the default AWS configuration
+func newClient(ctx context.Context) *gorson.Client {
+	client, err := gorson.New(ctx, nil)
+	if err != nil {
+		fail(err)
+	}
+	return protectClient(client)
 }
 
 // Execute runs the root command
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C and SIGTERM cancel the context commands run with, stopping in-flight parameter store calls
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		log.Fatal(err)
 	}
 }
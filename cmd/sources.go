@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pbs/gorson/internal/gorson/cli"
+	"github.com/pbs/gorson/pkg/gorson"
+	"github.com/spf13/cobra"
+)
+
+var noResolve bool
+var explain bool
+
+// addResolveFlag adds --no-resolve to a command that reads parameters
+func addResolveFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&noResolve, "no-resolve", false, "keep references like ${OTHER_KEY} and {{ssm:/shared/db/host}} in values as they are, instead of resolving them")
+}
+
+// addSourceFlags adds the flags of a command that reads and merges parameters from several sources
+func addSourceFlags(cmd *cobra.Command) {
+	addResolveFlag(cmd)
+	cmd.Flags().BoolVar(&explain, "explain", false, "print which source each key came from, and which earlier sources it overrode, to stderr")
+}
+
+// source is a parameter store path or a local file to read parameters from
+type source struct {
+	name string
+	file bool
+}
+
+// parseSource tells a local file from a parameter store path by an explicit marker, never by guessing:
+// files start with file:, ./ or ../, and paths start with ssm:. Unmarked sources are files when files is set,
+// as load has always read them. Otherwise they're paths, and one that also exists as a local file is ambiguous, and an error.
+func parseSource(arg string, files bool) (source, error) {
+	if name, ok := strings.CutPrefix(arg, "file:"); ok {
+		return source{name: name, file: true}, nil
+	}
+	if name, ok := strings.CutPrefix(arg, "ssm:"); ok {
+		return source{name: name}, nil
+	}
+	if files || strings.HasPrefix(arg, "./") || strings.HasPrefix(arg, "../") {
+		return source{name: arg, file: true}, nil
+	}
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		return source{}, fmt.Errorf("%s could be a parameter store path or a local file, write file:%s for the file or ssm:%s for the path", arg, arg, arg)
+	}
+	return source{name: arg}, nil
+}
+
+// parseSources parses every source argument, failing on the first ambiguous one. See parseSource for files.
+func parseSources(args []string, files bool) []source {
+	sources := make([]source, len(args))
+	for i, arg := range args {
+		s, err := parseSource(arg, files)
+		if err != nil {
+			fail(err)
+		}
+		sources[i] = s
+	}
+	return sources
+}
+
+// requireParameters fails with gorson.ErrNotFound when nothing was read from a parameter store path
+func requireParameters(path string, count int) {
+	if count == 0 {
+		fail(fmt.Errorf("%w: no parameters at %s", gorson.ErrNotFound, path))
+	}
+}
+
+// sourceName names the parameters from a source, like app-prod for /app/prod/ or example for ./example.json
+func sourceName(s source) string {
+	if s.file {
+		return strings.TrimSuffix(filepath.Base(s.name), filepath.Ext(s.name))
+	}
+	return s.name
+}
+
+// readSources reads parameters from parameter store paths and files, merged left to right so later sources win,
+// and resolves references in their values when resolve is set. When required is set, a path with no parameters
+// fails like a missing parameter, since it's most likely mistyped.
+func readSources(ctx context.Context, sources []source, resolve bool, required bool) map[string]string {
+	// the client is only created for a path or a reference, so files alone don't need AWS configuration
+	var client *gorson.Client
+	getClient := func() *gorson.Client {
+		if client == nil {
+			client = newClient(ctx)
+		}
+		return client
+	}
+
+	layers := make([]gorson.Layer, 0, len(sources))
+	for _, s := range sources {
+		name := s.name
+		var pms map[string]string
+		var err error
+		if s.file {
+			pms, err = gorson.ReadFile(name, inputFormat)
+		} else {
+			name = cli.NormalizePath(name)
+			pms, err = getClient().Get(ctx, name, gorson.GetOptions{Recursive: recursive})
+		}
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", name, err))
+		}
+		if required && !s.file {
+			requireParameters(name, len(pms))
+		}
+		layers = append(layers, gorson.Layer{Source: name, Parameters: pms})
+	}
+	pms, explanation := gorson.Merge(layers)
+	if explain {
+		fmt.Fprintln(os.Stderr, explanation.Text())
+	}
+
+	if !resolve || !gorson.HasReferences(pms) {
+		return pms
+	}
+	// ${KEY} can point at any parameter at a single path, but only at a merged key across several sources
+	path := ""
+	if len(sources) == 1 && !sources[0].file {
+		path = sources[0].name
+	}
+	resolved, err := getClient().Resolve(ctx, pms, path)
+	if err != nil {
+		fail(err)
+	}
+	return resolved
+}
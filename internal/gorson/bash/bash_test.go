@@ -1,6 +1,11 @@
 package bash
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	"github.com/pbs/gorson/internal/gorson/util"
+)
 
 type testpair struct {
 	input    map[string]string
@@ -38,8 +43,18 @@ export delta='the_delta_value'`,
 
 func TestParamsToShell(t *testing.T) {
 	for _, pair := range testpairs {
-		output := ParamsToShell(pair.input)
-		if pair.expected != "" && output != pair.expected {
+		output, err := ParamsToShell(pair.input)
+		if pair.expected == "" {
+			var invalidKey *util.InvalidKeyError
+			if !errors.As(err, &invalidKey) {
+				t.Error("For", pair.input, "expected an invalid key error, got", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error("For", pair.input, "unexpected error", err)
+		}
+		if output != pair.expected {
 			t.Error(
 				"For", pair.input,
 				"expected", pair.expected,
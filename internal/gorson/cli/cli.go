@@ -0,0 +1,101 @@
+// Package cli holds the helpers only the gorson command line tool needs, so they stay out of pkg/gorson's API
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pbs/gorson/internal/gorson/config"
+	"github.com/pbs/gorson/internal/gorson/diff"
+	"github.com/pbs/gorson/internal/gorson/env"
+	"github.com/pbs/gorson/internal/gorson/format"
+	"github.com/pbs/gorson/internal/gorson/history"
+	gorsonio "github.com/pbs/gorson/internal/gorson/io"
+	"github.com/pbs/gorson/internal/gorson/json"
+	"github.com/pbs/gorson/internal/gorson/k8s"
+	"github.com/pbs/gorson/internal/gorson/plan"
+	"github.com/pbs/gorson/internal/gorson/prompt"
+	"github.com/pbs/gorson/internal/gorson/util"
+	"gopkg.in/yaml.v2"
+)
+
+// Config is gorson's yaml config file
+type Config = config.Config
+
+// DefaultConfigFile is the config file gorson reads from the current directory, when there is one
+const DefaultConfigFile = config.DefaultFile
+
+// ReadConfig reads a yaml config file. A missing file's error wraps os.ErrNotExist, so callers can treat it as optional.
+func ReadConfig(filename string) (Config, error) {
+	return config.Read(filename)
+}
+
+// NormalizePath cleans up a parameter store path the way gorson reads and writes it, like /app/prod/ from app//prod
+func NormalizePath(path string) string {
+	return util.NewParameterStorePath(path).String()
+}
+
+// DiffText renders changes as colored, human-readable lines, with values masked unless showValues is set
+func DiffText(changes []diff.Change, showValues bool) string {
+	return diff.Text(changes, showValues)
+}
+
+// DiffJSON renders changes as an indented json document, with values masked unless showValues is set
+func DiffJSON(changes []diff.Change, showValues bool) (string, error) {
+	return diff.JSON(changes, showValues)
+}
+
+// HistoryText renders the history of each key at a path, newest version first, with values masked unless showValues is set
+func HistoryText(versions map[string][]gorsonio.Version, path string, showValues bool) string {
+	return history.Text(versions, NormalizePath(path), showValues)
+}
+
+// HistoryJSON renders the history of each key at a path as an indented json document, with values masked unless showValues is set
+func HistoryJSON(versions map[string][]gorsonio.Version, path string, showValues bool) (string, error) {
+	return history.JSON(versions, NormalizePath(path), showValues)
+}
+
+// ApproveDeletes shows the plan on out, with its values when showValues is set, then reads from in which of its deletes
+// to go ahead with. See prompt.ApproveDeletes.
+func ApproveDeletes(pl plan.Plan, showValues bool, in io.Reader, out io.Writer) ([]string, error) {
+	return prompt.ApproveDeletes(pl, showValues, in, out)
+}
+
+// IsTerminal reports whether a file, like os.Stdin, is an interactive terminal that ApproveDeletes can ask on
+func IsTerminal(f *os.File) bool {
+	return util.IsTerminal(f)
+}
+
+// EnvKeys converts relative parameter names like db/host into environment variable style keys like DB_HOST.
+// Names that convert to the same key result in an error.
+func EnvKeys(parameters map[string]string) (map[string]string, error) {
+	return util.EnvKeys(parameters)
+}
+
+// FormatNested serializes parameters as json or yaml (or yml), with keys like db/host nested as objects
+func FormatNested(parameters map[string]string, name string) (string, error) {
+	nested, err := util.Nest(parameters)
+	if err != nil {
+		return "", err
+	}
+	switch name {
+	case "json":
+		return json.MarshalNested(nested)
+	case "yaml", "yml":
+		serialized, err := yaml.Marshal(nested)
+		return string(serialized), err
+	}
+	return "", fmt.Errorf("%w: %s can't hold nested keys (yaml, json allowed)", format.ErrUnknownFormat, name)
+}
+
+// ManifestName makes a Kubernetes manifest name from a parameter store path, like app-prod from /app/prod/
+func ManifestName(path string) string {
+	return k8s.NameFromPath(path)
+}
+
+// MergeEnviron adds parameters to an environment in os.Environ's KEY=value form.
+// When override is false, variables already present in the environment keep their values.
+func MergeEnviron(environ []string, parameters map[string]string, override bool) []string {
+	return env.Merge(environ, parameters, override)
+}
@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pbs/gorson/internal/gorson/format"
+)
+
+func TestFormatNested(t *testing.T) {
+	output, err := FormatNested(map[string]string{"db/host": "a", "port": "b"}, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, `"db": {`) || !strings.Contains(output, `"host": "a"`) {
+		t.Errorf("expected db/host nested, got %s", output)
+	}
+	if _, err := FormatNested(map[string]string{"port": "b"}, "env"); !errors.Is(err, format.ErrUnknownFormat) {
+		t.Errorf("expected %v for a format that can't nest, got %v", format.ErrUnknownFormat, err)
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pbs/gorson/internal/gorson/io"
+	"github.com/pbs/gorson/internal/gorson/util"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultFile is the config file gorson reads from the current directory, when there is one
+const DefaultFile = ".gorson.yaml"
+
+// Config is gorson's config file
+type Config struct {
+	// Protected lists glob patterns of keys that are never deleted, like keys managed by other tools. See util.IsProtected.
+	Protected []string `yaml:"protected"`
+}
+
+// Read reads a yaml config file. A missing file's error wraps os.ErrNotExist, so callers can treat it as optional.
+func Read(filename string) (Config, error) {
+	var c Config
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return c, fmt.Errorf("%w: %w", io.ErrInvalidFile, err)
+	}
+	if err := yaml.UnmarshalStrict(content, &c); err != nil {
+		return c, fmt.Errorf("%w: error reading %s: %w", io.ErrInvalidFile, filename, err)
+	}
+	if err := util.ValidatePatterns(c.Protected); err != nil {
+		return c, fmt.Errorf("%w: error reading %s: %w", io.ErrInvalidFile, filename, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pbs/gorson/internal/gorson/io"
+)
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name     string
+		content  string
+		expected Config
+		err      error
+	}{
+		{"protected patterns", "protected:\n  - terraform/*\n  - /app/*/db_password\n", Config{Protected: []string{"terraform/*", "/app/*/db_password"}}, nil},
+		{"empty", "", Config{}, nil},
+		{"unknown setting", "protect:\n  - terraform/*\n", Config{}, io.ErrInvalidFile},
+		{"invalid pattern", "protected:\n  - db[_\n", Config{}, io.ErrInvalidFile},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filename := filepath.Join(dir, c.name+".yaml")
+			if err := os.WriteFile(filename, []byte(c.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			config, err := Read(filename)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("expected %v, got %v", c.err, err)
+			}
+			if c.err == nil && !reflect.DeepEqual(c.expected, config) {
+				t.Errorf("expected %v, got %v", c.expected, config)
+			}
+		})
+	}
+
+	if _, err := Read(filepath.Join(dir, "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected %v for a missing file, got %v", os.ErrNotExist, err)
+	}
+}
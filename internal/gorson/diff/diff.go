@@ -0,0 +1,147 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Kind describes how a single key differs between local parameters and parameter store
+type Kind string
+
+const (
+	// Added keys are present locally, but not in parameter store
+	Added Kind = "added"
+	// Changed keys are present in both places with different values
+	Changed Kind = "changed"
+	// Removed keys are present in parameter store, but not locally
+	Removed Kind = "removed"
+	// Unchanged keys are present in both places with the same value
+	Unchanged Kind = "unchanged"
+)
+
+// maskedValue replaces parameter values in output unless they are explicitly revealed
+const maskedValue = "********"
+
+// Change is the difference for a single key. Old is the value in parameter store,
+// New is the local value.
+type Change struct {
+	Key  string `json:"key"`
+	Kind Kind   `json:"kind"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// Compute compares local parameters against parameter store parameters, returning one change per key sorted by key
+func Compute(local map[string]string, remote map[string]string) []Change {
+	changes := make([]Change, 0, len(local)+len(remote))
+	for key, value := range local {
+		old, ok := remote[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: key, Kind: Added, New: value})
+		case old != value:
+			changes = append(changes, Change{Key: key, Kind: Changed, Old: old, New: value})
+		default:
+			changes = append(changes, Change{Key: key, Kind: Unchanged, Old: old, New: value})
+		}
+	}
+	for key, old := range remote {
+		if _, ok := local[key]; !ok {
+			changes = append(changes, Change{Key: key, Kind: Removed, Old: old})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// Keys returns the keys of all changes of the given kind
+func Keys(changes []Change, kind Kind) []string {
+	keys := make([]string, 0)
+	for _, c := range changes {
+		if c.Kind == kind {
+			keys = append(keys, c.Key)
+		}
+	}
+	return keys
+}
+
+// HasDrift reports whether any key differs between local parameters and parameter store
+func HasDrift(changes []Change) bool {
+	for _, c := range changes {
+		if c.Kind != Unchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// mask hides the values of a change unless showValues is set. Empty values are masked too,
+// so the output doesn't tell which keys are empty.
+func mask(c Change, showValues bool) Change {
+	if showValues {
+		return c
+	}
+	if c.Kind != Added {
+		c.Old = maskedValue
+	}
+	if c.Kind != Removed {
+		c.New = maskedValue
+	}
+	return c
+}
+
+// Text renders changes as a colored, human-readable diff. Unchanged keys are only counted.
+func Text(changes []Change, showValues bool) string {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	counts := make(map[Kind]int)
+	lines := make([]string, 0, len(changes)+1)
+	for _, c := range changes {
+		counts[c.Kind]++
+		c = mask(c, showValues)
+		switch c.Kind {
+		case Added:
+			lines = append(lines, green(fmt.Sprintf("+ %s = %s", c.Key, c.New)))
+		case Changed:
+			lines = append(lines, yellow(fmt.Sprintf("~ %s = %s -> %s", c.Key, c.Old, c.New)))
+		case Removed:
+			lines = append(lines, red(fmt.Sprintf("- %s = %s", c.Key, c.Old)))
+		}
+	}
+	summary := fmt.Sprintf("%d added, %d changed, %d removed, %d unchanged",
+		counts[Added], counts[Changed], counts[Removed], counts[Unchanged])
+	lines = append(lines, summary)
+	return strings.Join(lines, "\n")
+}
+
+// JSON renders changes as an indented json document for machine consumption
+func JSON(changes []Change, showValues bool) (string, error) {
+	masked := make([]Change, len(changes))
+	for i, c := range changes {
+		masked[i] = mask(c, showValues)
+	}
+	output := struct {
+		Drift   bool     `json:"drift"`
+		Changes []Change `json:"changes"`
+	}{
+		Drift:   HasDrift(changes),
+		Changes: masked,
+	}
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(&output); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
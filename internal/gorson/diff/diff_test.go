@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+type computeTestCase struct {
+	local    map[string]string
+	remote   map[string]string
+	expected []Change
+}
+
+var computeTestCases = []computeTestCase{
+	{
+		local:    map[string]string{},
+		remote:   map[string]string{},
+		expected: []Change{},
+	},
+	{
+		local:  map[string]string{"alpha": "one", "beta": "two", "gamma": "three"},
+		remote: map[string]string{"beta": "two", "gamma": "four", "delta": "five"},
+		expected: []Change{
+			{Key: "alpha", Kind: Added, New: "one"},
+			{Key: "beta", Kind: Unchanged, Old: "two", New: "two"},
+			{Key: "delta", Kind: Removed, Old: "five"},
+			{Key: "gamma", Kind: Changed, Old: "four", New: "three"},
+		},
+	},
+}
+
+func TestCompute(t *testing.T) {
+	for i, c := range computeTestCases {
+		changes := Compute(c.local, c.remote)
+		if !reflect.DeepEqual(c.expected, changes) {
+			t.Fatalf("%d expected %v, got %v", i, c.expected, changes)
+		}
+	}
+}
+
+func TestHasDrift(t *testing.T) {
+	if HasDrift(Compute(map[string]string{"a": "1"}, map[string]string{"a": "1"})) {
+		t.Error("expected no drift for identical parameters")
+	}
+	if !HasDrift(Compute(map[string]string{"a": "1"}, map[string]string{"a": "2"})) {
+		t.Error("expected drift for changed parameters")
+	}
+}
+
+func TestText(t *testing.T) {
+	color.NoColor = true
+	changes := computeTestCases[1].expected
+
+	masked := Text(changes, false)
+	expected := `+ alpha = ********
+- delta = ********
+~ gamma = ******** -> ********
+1 added, 1 changed, 1 removed, 1 unchanged`
+	if masked != expected {
+		t.Errorf("expected %s, got %s", expected, masked)
+	}
+
+	revealed := Text(changes, true)
+	if !strings.Contains(revealed, "~ gamma = four -> three") {
+		t.Errorf("expected revealed values, got %s", revealed)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	output, err := JSON([]Change{{Key: "alpha", Kind: Added, New: "one"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+    "drift": true,
+    "changes": [
+        {
+            "key": "alpha",
+            "kind": "added",
+            "new": "********"
+        }
+    ]
+}
+`
+	if output != expected {
+		t.Errorf("expected %s, got %s", expected, output)
+	}
+}
+
+func TestMaskEmptyValues(t *testing.T) {
+	color.NoColor = true
+	changes := Compute(map[string]string{"added": "", "changed": ""}, map[string]string{"changed": "x", "removed": ""})
+
+	expected := `+ added = ********
+~ changed = ******** -> ********
+- removed = ********
+1 added, 1 changed, 1 removed, 0 unchanged`
+	if masked := Text(changes, false); masked != expected {
+		t.Errorf("expected %s, got %s", expected, masked)
+	}
+
+	output, err := JSON(changes, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(output, `"********"`) != 4 {
+		t.Errorf("expected every value to be masked, got %s", output)
+	}
+}
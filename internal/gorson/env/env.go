@@ -1,17 +1,203 @@
 package env
 
 import (
-	"log"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/pbs/gorson/internal/gorson/util"
+	"golang.org/x/exp/maps"
 )
 
 // Marshal env-formats parameters
-func Marshal(parameters map[string]string) string {
+func Marshal(parameters map[string]string) (string, error) {
 	lines, err := util.ParametersToSlice(parameters)
 	if err != nil {
-		log.Printf("ParametersToSlice returned %s. Check validity of output for shell.", err.Error())
+		return "", err
 	}
-	return strings.Join(lines, "\n")
+	return strings.Join(lines, "\n"), nil
+}
+
+// Unmarshal parses env-formatted parameters, one KEY=value per line, as written by Marshal.
+// It accepts the same keys Marshal writes, so keys like db-host that aren't environment variable names come back too.
+// Values follow shell quoting: single quoted values are taken literally, and may span lines.
+// A single quote inside one closes the quotes, escapes the quote with a backslash and reopens them.
+// Unquoted and double quoted values, blank lines, # comments and a leading export are also accepted, as found in hand-written .env files.
+func Unmarshal(content string) (map[string]string, error) {
+	parameters := make(map[string]string)
+	p := &parser{input: []rune(content), line: 1}
+	for {
+		p.skipBlank()
+		if p.done() {
+			return parameters, nil
+		}
+		line := p.line
+		assignment := p.word()
+		if assignment == "export" {
+			p.skipSpaces()
+			assignment = p.word()
+		}
+		key, _, ok := strings.Cut(assignment, "=")
+		if !ok || !util.IsLineKey(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=value", line)
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		parameters[key] = value
+		p.skipSpaces()
+		if !p.done() && p.peek() == '#' {
+			p.skipComment()
+		}
+		if !p.done() && p.peek() != '\n' {
+			return nil, fmt.Errorf("line %d: unexpected text after the value of %s", p.line, key)
+		}
+	}
+}
+
+// parser reads env-formatted content a rune at a time
+type parser struct {
+	input []rune
+	pos   int
+	line  int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() rune {
+	return p.input[p.pos]
+}
+
+func (p *parser) next() rune {
+	r := p.input[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+func (p *parser) skipSpaces() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\r') {
+		p.next()
+	}
+}
+
+func (p *parser) skipComment() {
+	for !p.done() && p.peek() != '\n' {
+		p.next()
+	}
+}
+
+// skipBlank skips whitespace, blank lines and comment lines
+func (p *parser) skipBlank() {
+	for !p.done() {
+		switch p.peek() {
+		case ' ', '\t', '\r', '\n':
+			p.next()
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+// word reads unquoted text up to whitespace or the first =, which it includes, leaving the value to be read
+func (p *parser) word() string {
+	var b strings.Builder
+	for !p.done() {
+		r := p.peek()
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+			break
+		}
+		b.WriteRune(p.next())
+		if r == '=' {
+			break
+		}
+	}
+	return b.String()
+}
+
+// value reads a shell word, joining its quoted and unquoted parts, up to unquoted whitespace
+func (p *parser) value() (string, error) {
+	var b strings.Builder
+	for !p.done() {
+		switch r := p.peek(); r {
+		case ' ', '\t', '\r', '\n':
+			return b.String(), nil
+		case '\'':
+			line := p.line
+			p.next()
+			for {
+				if p.done() {
+					return "", fmt.Errorf("line %d: unterminated single quote", line)
+				}
+				c := p.next()
+				if c == '\'' {
+					break
+				}
+				b.WriteRune(c)
+			}
+		case '"':
+			line := p.line
+			p.next()
+			for {
+				if p.done() {
+					return "", fmt.Errorf("line %d: unterminated double quote", line)
+				}
+				c := p.next()
+				if c == '"' {
+					break
+				}
+				if c == '\\' && !p.done() {
+					switch e := p.next(); e {
+					case 'n':
+						c = '\n'
+					case '"', '\\', '$', '`':
+						c = e
+					default:
+						b.WriteRune(c)
+						c = e
+					}
+				}
+				b.WriteRune(c)
+			}
+		case '\\':
+			p.next()
+			if !p.done() {
+				b.WriteRune(p.next())
+			}
+		default:
+			b.WriteRune(p.next())
+		}
+	}
+	return b.String(), nil
+}
+
+// Merge adds parameters to an environment in os.Environ's KEY=value form.
+// When override is false, variables already present in the environment keep their values.
+func Merge(environ []string, parameters map[string]string, override bool) []string {
+	merged := make([]string, 0, len(environ)+len(parameters))
+	existing := make(map[string]bool, len(environ))
+	for _, variable := range environ {
+		key := strings.SplitN(variable, "=", 2)[0]
+		existing[key] = true
+		if _, ok := parameters[key]; ok && override {
+			continue
+		}
+		merged = append(merged, variable)
+	}
+	keys := maps.Keys(parameters)
+	sort.Strings(keys)
+	for _, key := range keys {
+		if existing[key] && !override {
+			continue
+		}
+		merged = append(merged, key+"="+parameters[key])
+	}
+	return merged
 }
@@ -1,6 +1,9 @@
 package env
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 type testpair struct {
 	input    map[string]string
@@ -24,7 +27,10 @@ var testpairs = []testpair{
 
 func TestMarshal(t *testing.T) {
 	for _, pair := range testpairs {
-		output := Marshal(pair.input)
+		output, err := Marshal(pair.input)
+		if err != nil {
+			t.Error("For", pair.input, "unexpected error", err)
+		}
 		if output != pair.expected {
 			t.Error(
 				"For", pair.input,
@@ -34,3 +40,93 @@ func TestMarshal(t *testing.T) {
 		}
 	}
 }
+
+var mergeTestCases = []struct {
+	environ    []string
+	parameters map[string]string
+	override   bool
+	expected   []string
+}{
+	{
+		environ:    []string{"HOME=/root", "PORT=80"},
+		parameters: map[string]string{"PORT": "8080", "SECRET": "a=b"},
+		override:   true,
+		expected:   []string{"HOME=/root", "PORT=8080", "SECRET=a=b"},
+	},
+	{
+		environ:    []string{"HOME=/root", "PORT=80"},
+		parameters: map[string]string{"PORT": "8080", "SECRET": "a=b"},
+		override:   false,
+		expected:   []string{"HOME=/root", "PORT=80", "SECRET=a=b"},
+	},
+}
+
+func TestMerge(t *testing.T) {
+	for i, c := range mergeTestCases {
+		output := Merge(c.environ, c.parameters, c.override)
+		if !reflect.DeepEqual(c.expected, output) {
+			t.Errorf("%d expected %v, got %v", i, c.expected, output)
+		}
+	}
+}
+
+var unmarshalTestCases = []struct {
+	input    string
+	expected map[string]string
+}{
+	{
+		input:    "ALPHA='one'\nBETA='two words'\n",
+		expected: map[string]string{"ALPHA": "one", "BETA": "two words"},
+	},
+	{
+		input:    "# a comment\n\nexport ALPHA=one # trailing comment\nBETA=\"say \\\"hi\\\"\\nbye\"\nGAMMA=\n",
+		expected: map[string]string{"ALPHA": "one", "BETA": "say \"hi\"\nbye", "GAMMA": ""},
+	},
+	{
+		input:    "EQUALS='a=b'\nCONCATENATED=a'b c'\"d\"",
+		expected: map[string]string{"EQUALS": "a=b", "CONCATENATED": "ab cd"},
+	},
+}
+
+func TestUnmarshal(t *testing.T) {
+	for i, c := range unmarshalTestCases {
+		output, err := Unmarshal(c.input)
+		if err != nil {
+			t.Fatalf("%d unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(c.expected, output) {
+			t.Errorf("%d expected %q, got %q", i, c.expected, output)
+		}
+	}
+
+	for _, input := range []string{"ALPHA='unterminated", "3ALPHA=one", "ALPHA", "ALPHA=one two"} {
+		if _, err := Unmarshal(input); err == nil {
+			t.Errorf("expected an error parsing %q", input)
+		}
+	}
+}
+
+// values Marshal writes come back unchanged from Unmarshal
+func TestMarshalRoundTrip(t *testing.T) {
+	parameters := map[string]string{
+		"SINGLE_QUOTES": "it's 'quoted'",
+		"DOUBLE_QUOTES": `say "hi"`,
+		"NEWLINES":      "line one\nline two\n",
+		"UNICODE":       "héllo wörld ✓",
+		"SHELL":         "$HOME `pwd` \\ & ; #",
+		"EMPTY":         "",
+		"db-host":       "not an environment variable name",
+		"app.name/key":  "still written and read back",
+	}
+	output, err := Marshal(parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Unmarshal(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parameters, parsed) {
+		t.Errorf("expected %q, got %q", parameters, parsed)
+	}
+}
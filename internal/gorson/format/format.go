@@ -0,0 +1,169 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/pbs/gorson/internal/gorson/bash"
+	"github.com/pbs/gorson/internal/gorson/env"
+	"github.com/pbs/gorson/internal/gorson/json"
+	"github.com/pbs/gorson/internal/gorson/k8s"
+	"github.com/pbs/gorson/internal/gorson/shell"
+	"github.com/pbs/gorson/internal/gorson/util"
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrUnknownFormat is returned when a format isn't in the registry
+var ErrUnknownFormat = errors.New("unknown format")
+
+// Options holds the settings only some formats use
+type Options struct {
+	// Manifest is the name, namespace and labels of k8s-secret and k8s-configmap manifests
+	Manifest k8s.Options
+}
+
+// Formatter serializes flat key/value parameters in a single format.
+// Keys the format can't hold are reported with a *util.InvalidKeyError.
+type Formatter interface {
+	Format(parameters map[string]string, opts Options) (string, error)
+}
+
+// Func adapts a function to a Formatter
+type Func func(parameters map[string]string, opts Options) (string, error)
+
+// Format calls f
+func (f Func) Format(parameters map[string]string, opts Options) (string, error) {
+	return f(parameters, opts)
+}
+
+// entry is a registered format
+type entry struct {
+	name        string
+	aliases     []string
+	description string
+	formatter   Formatter
+}
+
+var (
+	mu sync.RWMutex
+	// entries holds registered formats in the order they were registered
+	entries []*entry
+	// byName finds an entry by its name or any of its aliases
+	byName = make(map[string]*entry)
+)
+
+// Register adds a format under a name and any aliases. It panics if a name is already taken,
+// as that is a programming error, like registering twice.
+func Register(name string, description string, formatter Formatter, aliases ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	e := &entry{name: name, aliases: aliases, description: description, formatter: formatter}
+	for _, n := range append([]string{name}, aliases...) {
+		if _, ok := byName[n]; ok {
+			panic("format: " + n + " registered twice")
+		}
+		byName[n] = e
+	}
+	entries = append(entries, e)
+}
+
+// Lookup finds a format by name or alias, failing with ErrUnknownFormat
+func Lookup(name string) (Formatter, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	e, ok := byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (%s allowed)", ErrUnknownFormat, name, strings.Join(names(), ", "))
+	}
+	return e.formatter, nil
+}
+
+// Format serializes parameters in a registered format
+func Format(parameters map[string]string, name string, opts Options) (string, error) {
+	formatter, err := Lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return formatter.Format(parameters, opts)
+}
+
+// Names lists every registered format, without aliases, in the order they were registered
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return names()
+}
+
+func names() []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.name
+	}
+	return out
+}
+
+// Help describes every registered format, one per line, with its aliases
+func Help() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	buf := new(strings.Builder)
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	for _, e := range entries {
+		name := e.name
+		if len(e.aliases) > 0 {
+			aliases := append([]string{}, e.aliases...)
+			sort.Strings(aliases)
+			name += " (" + strings.Join(aliases, ", ") + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", name, e.description)
+	}
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// plain adapts a serializer that takes no options to a Formatter
+func plain(f func(parameters map[string]string) (string, error)) Formatter {
+	return Func(func(parameters map[string]string, opts Options) (string, error) {
+		return f(parameters)
+	})
+}
+
+// shellScript adapts a script generator to a Formatter that first checks every key is an environment variable name,
+// as no shell can set any other, so every shell rejects the same keys
+func shellScript(f func(parameters map[string]string) (string, error)) Formatter {
+	return Func(func(parameters map[string]string, opts Options) (string, error) {
+		keys := maps.Keys(parameters)
+		sort.Strings(keys)
+		for _, key := range keys {
+			if !util.IsEnvKey(key) {
+				return "", &util.InvalidKeyError{Key: key}
+			}
+		}
+		return f(parameters)
+	})
+}
+
+func init() {
+	Register("json", "a json object of keys and values", plain(json.Marshal))
+	Register("yaml", "a yaml mapping of keys and values", plain(func(parameters map[string]string) (string, error) {
+		serialized, err := yaml.Marshal(parameters)
+		return string(serialized), err
+	}), "yml")
+	Register("env", "KEY='value' lines, as read by put and exec", plain(env.Marshal))
+	Register("bash", "export KEY='value' lines for bash, sh and zsh", shellScript(bash.ParamsToShell), "sh", "zsh")
+	Register("fish", "set -gx KEY 'value' lines for fish", shellScript(shell.Fish))
+	Register("powershell", "$env:KEY = 'value' lines for PowerShell", shellScript(shell.PowerShell), "pwsh")
+	Register("nushell", "a load-env record for nushell", shellScript(shell.Nushell), "nu")
+	Register("csh", "setenv KEY 'value' lines for csh and tcsh", shellScript(shell.Csh), "tcsh")
+	Register("k8s-secret", "a Kubernetes Secret manifest, with base64 encoded values", Func(func(parameters map[string]string, opts Options) (string, error) {
+		return k8s.Secret(parameters, opts.Manifest)
+	}))
+	Register("k8s-configmap", "a Kubernetes ConfigMap manifest", Func(func(parameters map[string]string, opts Options) (string, error) {
+		return k8s.ConfigMap(parameters, opts.Manifest)
+	}))
+}
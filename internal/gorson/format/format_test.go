@@ -0,0 +1,103 @@
+package format
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pbs/gorson/internal/gorson/k8s"
+	"github.com/pbs/gorson/internal/gorson/util"
+)
+
+func TestFormat(t *testing.T) {
+	parameters := map[string]string{"alpha": "one"}
+	opts := Options{Manifest: k8s.Options{Name: "app"}}
+	cases := map[string]string{
+		"json":          "{\n    \"alpha\": \"one\"\n}\n",
+		"yaml":          "alpha: one\n",
+		"yml":           "alpha: one\n",
+		"env":           "alpha='one'",
+		"bash":          "export alpha='one'",
+		"zsh":           "export alpha='one'",
+		"fish":          "set -gx alpha 'one'",
+		"pwsh":          "$env:alpha = 'one'",
+		"nu":            "load-env {\n    alpha: \"one\"\n}",
+		"tcsh":          "setenv alpha 'one'",
+		"k8s-configmap": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  alpha: one\n",
+	}
+	for name, expected := range cases {
+		output, err := Format(parameters, name, opts)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+		if output != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, output)
+		}
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	_, err := Format(map[string]string{"alpha": "one"}, "xml", Options{})
+	if !errors.Is(err, ErrUnknownFormat) || !strings.Contains(err.Error(), "json, yaml, env") {
+		t.Errorf("expected %v listing the formats, got %v", ErrUnknownFormat, err)
+	}
+
+	// every format either holds a key, or says it can't with an InvalidKeyError
+	holds := map[string]bool{"json": true, "yaml": true, "k8s-secret": true, "k8s-configmap": true}
+	for _, name := range Names() {
+		_, err := Format(map[string]string{"1host": "value"}, name, Options{Manifest: k8s.Options{Name: "app"}})
+		var invalidKey *util.InvalidKeyError
+		if holds[name] && err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+		if !holds[name] && !errors.As(err, &invalidKey) {
+			t.Errorf("%s: expected an invalid key error, got %v", name, err)
+		}
+	}
+}
+
+func TestShellKeys(t *testing.T) {
+	for _, name := range []string{"bash", "fish", "powershell", "nushell", "csh"} {
+		for _, key := range []string{"db.host", "db/host", "db-host"} {
+			_, err := Format(map[string]string{key: "value"}, name, Options{})
+			var invalidKey *util.InvalidKeyError
+			if !errors.As(err, &invalidKey) || invalidKey.Key != key {
+				t.Errorf("%s: expected an invalid key error for %s, got %v", name, key, err)
+			}
+		}
+	}
+	if _, err := Format(map[string]string{"db-host": "value"}, "env", Options{}); err != nil {
+		t.Errorf("expected env to hold db-host, got %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-upper", "upper cased values", Func(func(parameters map[string]string, opts Options) (string, error) {
+		return strings.ToUpper(parameters["alpha"]), nil
+	}), "test-shout")
+	if output, err := Format(map[string]string{"alpha": "one"}, "test-shout", Options{}); err != nil || output != "ONE" {
+		t.Errorf("expected ONE, got %q and %v", output, err)
+	}
+	names := Names()
+	if names[len(names)-1] != "test-upper" {
+		t.Errorf("expected test-upper listed last, got %v", names)
+	}
+	if !strings.Contains(Help(), "test-upper (test-shout)  upper cased values") {
+		t.Errorf("expected test-upper described, got\n%s", Help())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a taken name to panic")
+		}
+	}()
+	Register("json", "again", Func(nil))
+}
+
+func TestNames(t *testing.T) {
+	expected := []string{"json", "yaml", "env", "bash", "fish", "powershell", "nushell", "csh", "k8s-secret", "k8s-configmap"}
+	if names := Names(); !reflect.DeepEqual(expected, names[:len(expected)]) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pbs/gorson/internal/gorson/io"
+	"golang.org/x/exp/maps"
+)
+
+// timeLayouts are the layouts a rollback target is parsed with, most specific first.
+// Layouts without a zone are in local time.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Target is the point in a parameter's history to roll back to: a time, or a version label
+type Target struct {
+	Time  time.Time
+	Label string
+}
+
+// ParseTarget reads a timestamp like 2026-10-17T12:00:00Z or 2026-10-17 12:00 as a time,
+// and anything else as a version label
+func ParseTarget(s string) Target {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return Target{Time: t}
+		}
+	}
+	return Target{Label: s}
+}
+
+func (t Target) String() string {
+	if t.Label != "" {
+		return "label " + t.Label
+	}
+	return t.Time.Format(time.RFC3339)
+}
+
+// Select returns the version current at the target: the latest version written at or before its time,
+// or the version holding its label. It returns false if there is none, like for a parameter created later.
+func Select(versions []io.Version, target Target) (io.Version, bool) {
+	var selected io.Version
+	found := false
+	for _, v := range versions {
+		if target.Label != "" {
+			if slices.Contains(v.Labels, target.Label) {
+				return v, true
+			}
+			continue
+		}
+		if !v.Modified.After(target.Time) && (!found || v.Version > selected.Version) {
+			selected = v
+			found = true
+		}
+	}
+	return selected, found
+}
+
+// mask hides a value unless showValues is set
+func mask(value string, showValues bool) string {
+	if showValues {
+		return value
+	}
+	return "********"
+}
+
+// Text renders the history of each key at a path, newest version first, as human-readable lines
+func Text(history map[string][]io.Version, path string, showValues bool) string {
+	bold := color.New(color.Bold).SprintFunc()
+	keys := maps.Keys(history)
+	sort.Strings(keys)
+	lines := make([]string, 0)
+	for _, k := range keys {
+		lines = append(lines, bold(path+k))
+		versions := history[k]
+		for i := len(versions) - 1; i >= 0; i-- {
+			v := versions[i]
+			line := fmt.Sprintf("  %d  %s  %s  %s = %s", v.Version, v.Modified.Format(time.RFC3339), v.ModifiedBy, v.Type, mask(v.Value, showValues))
+			if len(v.Labels) > 0 {
+				line += fmt.Sprintf("  [%s]", strings.Join(v.Labels, ", "))
+			}
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON renders the history of each key at a path as an indented json document for machine consumption
+func JSON(history map[string][]io.Version, path string, showValues bool) (string, error) {
+	masked := make(map[string][]io.Version, len(history))
+	for k, versions := range history {
+		masked[k] = make([]io.Version, len(versions))
+		for i, v := range versions {
+			v.Value = mask(v.Value, showValues)
+			masked[k][i] = v
+		}
+	}
+	output := struct {
+		Path    string                  `json:"path"`
+		History map[string][]io.Version `json:"history"`
+	}{path, masked}
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(&output); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,76 @@
+package history
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/fatih/color"
+	"github.com/pbs/gorson/internal/gorson/io"
+)
+
+var monday = time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+
+var versions = []io.Version{
+	{Parameter: io.Parameter{Value: "one", Type: types.ParameterTypeSecureString}, Version: 1, Modified: monday, ModifiedBy: "alice", Labels: []string{"release"}},
+	{Parameter: io.Parameter{Value: "two", Type: types.ParameterTypeSecureString}, Version: 2, Modified: monday.AddDate(0, 0, 1), ModifiedBy: "bob"},
+	{Parameter: io.Parameter{Value: "three", Type: types.ParameterTypeSecureString}, Version: 3, Modified: monday.AddDate(0, 0, 2), ModifiedBy: "alice"},
+}
+
+func TestParseTarget(t *testing.T) {
+	cases := map[string]Target{
+		"2026-10-12T09:00:00Z": {Time: monday},
+		"2026-10-12":           {Time: time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)},
+		"2026-10-12 09:30":     {Time: time.Date(2026, 10, 12, 9, 30, 0, 0, time.Local)},
+		"release":              {Label: "release"},
+	}
+	for input, expected := range cases {
+		if target := ParseTarget(input); !target.Time.Equal(expected.Time) || target.Label != expected.Label {
+			t.Errorf("%s expected %v, got %v", input, expected, target)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	cases := []struct {
+		target   Target
+		expected int64
+		found    bool
+	}{
+		{Target{Time: monday.Add(-time.Hour)}, 0, false},
+		{Target{Time: monday}, 1, true},
+		{Target{Time: monday.AddDate(0, 0, 1).Add(time.Hour)}, 2, true},
+		{Target{Time: monday.AddDate(1, 0, 0)}, 3, true},
+		{Target{Label: "release"}, 1, true},
+		{Target{Label: "missing"}, 0, false},
+	}
+	for i, c := range cases {
+		v, found := Select(versions, c.target)
+		if found != c.found || v.Version != c.expected {
+			t.Errorf("%d expected version %d (%v), got %d (%v)", i, c.expected, c.found, v.Version, found)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	color.NoColor = true
+	output := Text(map[string][]io.Version{"alpha": versions[:1]}, "/path/", false)
+	expected := "/path/alpha\n  1  2026-10-12T09:00:00Z  alice  SecureString = ********  [release]"
+	if output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	output, err := JSON(map[string][]io.Version{"alpha": versions}, "/path/", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output, `"two"`) || !strings.Contains(output, `"modified_by": "bob"`) {
+		t.Errorf("expected masked values and modifiers, got %s", output)
+	}
+	if versions[1].Value != "two" {
+		t.Error("masking should not change the history it was given")
+	}
+}
@@ -0,0 +1,49 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/smithy-go"
+)
+
+var (
+	// ErrNotFound is returned when a parameter does not exist
+	ErrNotFound = errors.New("parameter not found")
+	// ErrAccessDenied is returned when the AWS credentials in use aren't allowed to perform an operation
+	ErrAccessDenied = errors.New("access denied")
+	// ErrThrottled is returned when parameter store keeps throttling requests after all retries
+	ErrThrottled = errors.New("throttled")
+	// ErrTimeout is returned when an operation doesn't finish before its context's deadline
+	ErrTimeout = errors.New("timeout")
+	// ErrInvalidFile is returned when a parameter file can't be read or parsed
+	ErrInvalidFile = errors.New("invalid file")
+	// ErrNotTerminal is returned when a prompt needs an answer, but stdin isn't a terminal to ask on
+	ErrNotTerminal = errors.New("stdin is not a terminal")
+)
+
+// unsupported is returned by operations that need more from an SSM client than it implements
+func unsupported(operation string) error {
+	return fmt.Errorf("%w: the SSM client can't %s", errors.ErrUnsupported, operation)
+}
+
+// classify wraps an AWS error with the sentinel error for its class, so callers can check it with errors.Is
+func classify(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch apiErr.ErrorCode() {
+	case "ParameterNotFound", "ParameterVersionNotFound":
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case "AccessDeniedException":
+		return fmt.Errorf("%w: %w", ErrAccessDenied, err)
+	case "ThrottlingException":
+		return fmt.Errorf("%w: %w", ErrThrottled, err)
+	}
+	return err
+}
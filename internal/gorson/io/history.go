@@ -0,0 +1,107 @@
+package io
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/pbs/gorson/internal/gorson/util"
+)
+
+// Version is a single version of a parameter from its history
+type Version struct {
+	Parameter
+	Version    int64     `json:"version" yaml:"version"`
+	Labels     []string  `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Modified   time.Time `json:"modified" yaml:"modified"`
+	ModifiedBy string    `json:"modified_by" yaml:"modified_by"`
+}
+
+// ReadHistory gets every version of a parameter, oldest first.
+// Requests keep to the rate and retry budget of opts, and throttled requests are retried like writes.
+func ReadHistory(ctx context.Context, name string, client SSMClient, opts WriteOptions) ([]Version, error) {
+	if _, ok := client.(HistoryClient); !ok {
+		return nil, unsupported("read parameter history")
+	}
+	return newWriter(client, opts).readHistory(ctx, name)
+}
+
+// readHistory gets every version of a parameter through the writer's rate limit and retries
+func (w *writer) readHistory(ctx context.Context, name string) ([]Version, error) {
+	historian := w.client.(HistoryClient)
+	withDecryption := true
+	input := ssm.GetParameterHistoryInput{
+		Name:           &name,
+		WithDecryption: &withDecryption,
+	}
+	versions := make([]Version, 0)
+	// loop until pagination done
+	for {
+		var output *ssm.GetParameterHistoryOutput
+		_, err := w.call(ctx, "reading the history of "+name, func() error {
+			var err error
+			output, err = historian.GetParameterHistory(ctx, &input)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range output.Parameters {
+			v := Version{
+				Parameter: Parameter{
+					Value:       aws.ToString(h.Value),
+					Type:        h.Type,
+					Description: aws.ToString(h.Description),
+				},
+				Version:    h.Version,
+				Labels:     h.Labels,
+				Modified:   aws.ToTime(h.LastModifiedDate),
+				ModifiedBy: aws.ToString(h.LastModifiedUser),
+			}
+			if v.Secure() {
+				v.KeyID = aws.ToString(h.KeyId)
+			}
+			versions = append(versions, v)
+		}
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
+}
+
+// ReadPathHistory gets every version of each parameter at a given parameter store path, keyed like ReadFromParameterStore.
+// Parameter store deletes the history of a parameter along with it, so only parameters that still exist have any.
+// Every request shares the rate limit and retry budget of opts.
+func ReadPathHistory(ctx context.Context, path util.ParameterStorePath, client SSMClient, opts WriteOptions) (map[string][]Version, error) {
+	if client == nil {
+		c, err := NewSSMClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		client = c
+	}
+	if _, ok := client.(HistoryClient); !ok {
+		return nil, unsupported("read parameter history")
+	}
+	parameters, err := readParameters(ctx, path, false, client)
+	if err != nil {
+		return nil, err
+	}
+	w := newWriter(client, opts)
+	history := make(map[string][]Version, len(parameters))
+	for k := range parameters {
+		versions, err := w.readHistory(ctx, path.String()+k)
+		if err != nil {
+			return nil, err
+		}
+		history[k] = versions
+	}
+	return history, nil
+}
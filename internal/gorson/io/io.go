@@ -1,78 +1,255 @@
 package io
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
 	"math"
 	"math/rand"
 	"os"
-	"reflect"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/fatih/color"
+	"github.com/pbs/gorson/internal/gorson/env"
 	"github.com/pbs/gorson/internal/gorson/util"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v2"
 )
 
-// SSMClient interface for mocking in tests
+// SSMClient is what reading, writing and deleting parameters needs from an SSM client.
+// Operations that need more assert one of the optional interfaces below, and fail with errors.ErrUnsupported without it.
 type SSMClient interface {
 	GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
 	PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
 	DeleteParameters(ctx context.Context, params *ssm.DeleteParametersInput, optFns ...func(*ssm.Options)) (*ssm.DeleteParametersOutput, error)
 }
 
-func getSSMClient() *ssm.Client {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+// DescribeClient is an SSM client that can describe parameters, which reading descriptions and KMS keys needs
+type DescribeClient interface {
+	DescribeParameters(ctx context.Context, params *ssm.DescribeParametersInput, optFns ...func(*ssm.Options)) (*ssm.DescribeParametersOutput, error)
+}
+
+// TagClient is an SSM client that can read and add tags, which copying tags needs
+type TagClient interface {
+	ListTagsForResource(ctx context.Context, params *ssm.ListTagsForResourceInput, optFns ...func(*ssm.Options)) (*ssm.ListTagsForResourceOutput, error)
+	AddTagsToResource(ctx context.Context, params *ssm.AddTagsToResourceInput, optFns ...func(*ssm.Options)) (*ssm.AddTagsToResourceOutput, error)
+}
+
+// HistoryClient is an SSM client that can read the versions of a parameter, which history and rollback need
+type HistoryClient interface {
+	GetParameterHistory(ctx context.Context, params *ssm.GetParameterHistoryInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterHistoryOutput, error)
+}
+
+// the AWS SSM client supports every optional interface
+var _ interface {
+	SSMClient
+	DescribeClient
+	TagClient
+	HistoryClient
+} = (*ssm.Client)(nil)
+
+// NewSSMClient returns an SSM client configured from the default AWS configuration in the environment.
+// Options like config.WithSharedConfigProfile or config.WithRegion override parts of it.
+func NewSSMClient(ctx context.Context, optFns ...func(*config.LoadOptions) error) (*ssm.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, optFns...)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	client := ssm.NewFromConfig(cfg)
-	return client
+	return client, nil
+}
+
+// DefaultKeyID is the AWS managed KMS key SecureString parameters are encrypted with when no other key is given
+const DefaultKeyID = "alias/aws/ssm"
+
+// Parameter is a parameter store value along with its type, description and KMS key.
+// An empty Type is written as a SecureString, and an empty KeyID as DefaultKeyID.
+type Parameter struct {
+	Value       string              `json:"value" yaml:"value"`
+	Type        types.ParameterType `json:"type,omitempty" yaml:"type,omitempty"`
+	Description string              `json:"description,omitempty" yaml:"description,omitempty"`
+	KeyID       string              `json:"kms_key_id,omitempty" yaml:"kms_key_id,omitempty"`
+}
+
+// Secure reports whether a parameter is written as a SecureString
+func (p Parameter) Secure() bool {
+	return p.Type == "" || p.Type == types.ParameterTypeSecureString
 }
 
 // ReadFromParameterStore gets all parameters from a given parameter store path
-func ReadFromParameterStore(path util.ParameterStorePath, client SSMClient) map[string]string {
+func ReadFromParameterStore(ctx context.Context, path util.ParameterStorePath, client SSMClient) (map[string]string, error) {
+	parameters, err := ReadParametersFromParameterStore(ctx, path, client)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(parameters))
+	for k, parameter := range parameters {
+		values[k] = parameter.Value
+	}
+	return values, nil
+}
+
+// ReadFromParameterStoreRecursive gets all parameters nested anywhere under a given parameter store path.
+// Keys are the parameter names relative to the path, like db/host for /a/path/db/host.
+func ReadFromParameterStoreRecursive(ctx context.Context, path util.ParameterStorePath, client SSMClient) (map[string]string, error) {
+	parameters, err := readParameters(ctx, path, true, client)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(parameters))
+	for k, parameter := range parameters {
+		values[k] = parameter.Value
+	}
+	return values, nil
+}
+
+// ReadParametersFromParameterStore gets all parameters, along with their types, from a given parameter store path
+func ReadParametersFromParameterStore(ctx context.Context, path util.ParameterStorePath, client SSMClient) (map[string]Parameter, error) {
+	return readParameters(ctx, path, false, client)
+}
+
+// ReadParametersWithMetadata gets all parameters from a given parameter store path along with their types,
+// descriptions and the KMS keys SecureStrings are encrypted with.
+// Descriptions take an extra DescribeParameters call per page of parameters, so only read them when needed.
+func ReadParametersWithMetadata(ctx context.Context, path util.ParameterStorePath, recursive bool, client SSMClient) (map[string]Parameter, error) {
 	if client == nil {
-		client = getSSMClient()
+		c, err := NewSSMClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		client = c
+	}
+	parameters, err := readParameters(ctx, path, recursive, client)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := describeParameters(ctx, path, recursive, client)
+	if err != nil {
+		return nil, err
+	}
+	for k, parameter := range parameters {
+		if m, ok := metadata[k]; ok {
+			parameter.Description = aws.ToString(m.Description)
+			if parameter.Secure() {
+				parameter.KeyID = aws.ToString(m.KeyId)
+			}
+			parameters[k] = parameter
+		}
+	}
+	return parameters, nil
+}
+
+// describeParameters gets the metadata of all parameters at a given parameter store path, keyed like readParameters
+func describeParameters(ctx context.Context, path util.ParameterStorePath, recursive bool, client SSMClient) (map[string]types.ParameterMetadata, error) {
+	describer, ok := client.(DescribeClient)
+	if !ok {
+		return nil, unsupported("describe parameters")
+	}
+	p := path.String()
+	option := "OneLevel"
+	if recursive {
+		option = "Recursive"
+	}
+	// the Path filter wants the path without its trailing slash, except for the root
+	filterPath := p
+	if len(filterPath) > 1 {
+		filterPath = strings.TrimSuffix(filterPath, "/")
+	}
+	input := ssm.DescribeParametersInput{
+		ParameterFilters: []types.ParameterStringFilter{
+			{
+				Key:    aws.String("Path"),
+				Option: aws.String(option),
+				Values: []string{filterPath},
+			},
+		},
+	}
+
+	metadata := make(map[string]types.ParameterMetadata)
+	// loop until pagination done
+	for {
+		output, err := describer.DescribeParameters(ctx, &input)
+		if err != nil {
+			return nil, classify(err)
+		}
+		for _, m := range output.Parameters {
+			name := aws.ToString(m.Name)
+			var k string
+			if recursive {
+				k = strings.TrimPrefix(name, p)
+			} else {
+				s := strings.Split(name, "/")
+				k = s[len(s)-1]
+			}
+			metadata[k] = m
+		}
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return metadata, nil
+}
+
+func readParameters(ctx context.Context, path util.ParameterStorePath, recursive bool, client SSMClient) (map[string]Parameter, error) {
+	if client == nil {
+		c, err := NewSSMClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		client = c
 	}
 
 	p := path.String()
 
 	var nextToken *string
-	values := make(map[string]string)
+	parameters := make(map[string]Parameter)
 
 	// loop until pagination done
 	for {
 		decr := true
 		input := ssm.GetParametersByPathInput{
 			Path:           &p,
+			Recursive:      &recursive,
 			WithDecryption: &decr,
 		}
 		if nextToken != nil {
 			input.NextToken = nextToken
 		}
-		output, err := client.GetParametersByPath(context.TODO(), &input)
+		output, err := client.GetParametersByPath(ctx, &input)
 		if err != nil {
-			log.Fatal(err)
+			return nil, classify(err)
 		}
 		outputParams := output.Parameters
 		for index := 0; index < len(outputParams); index++ {
 			o := outputParams[index]
 			// we remove the leading path, we want the last element of the
 			// slash-delimited path as the key in our key/value pair.
-			s := strings.Split(*o.Name, "/")
-			k := s[len(s)-1]
-			values[k] = *o.Value
+			// recursive reads keep everything below the path instead, so
+			// /a/path/db/host and /a/path/cache/host can't collide.
+			var k string
+			if recursive {
+				k = strings.TrimPrefix(*o.Name, p)
+			} else {
+				s := strings.Split(*o.Name, "/")
+				k = s[len(s)-1]
+			}
+			parameters[k] = Parameter{
+				Value: *o.Value,
+				Type:  o.Type,
+			}
 		}
 
 		// we're done paginating, break out of the loop
@@ -81,132 +258,212 @@ func ReadFromParameterStore(path util.ParameterStorePath, client SSMClient) map[
 		}
 		nextToken = output.NextToken
 	}
-	return values
+	return parameters, nil
 }
 
 // WriteResult is the result writing a single parameter - successful if Error is nil
 type WriteResult struct {
 	Name  string
 	Error error
+	// Retries is how many times the write was retried after being throttled
+	Retries int
+}
+
+// defaults for WriteOptions fields that are left unset
+const (
+	DefaultConcurrency = 10
+	DefaultRetryBudget = 100
+)
+
+const (
+	// maxRetries bounds how many times a single throttled parameter is retried
+	maxRetries = 10
+	// maxBackoff caps the wait between retries, however many times a parameter was throttled
+	maxBackoff = 5 * time.Second
+)
+
+// WriteOptions bounds how hard WriteToParameterStore pushes on parameter store.
+// Deleting parameters, reading or writing their tags and reading their history keep to its Rate and RetryBudget too.
+type WriteOptions struct {
+	// Concurrency is how many parameters are written at once, DefaultConcurrency if unset
+	Concurrency int
+	// Rate is the most requests per second, retries included. Zero means no limit.
+	Rate float64
+	// RetryBudget is how many throttled writes may be retried in total, across all parameters, DefaultRetryBudget if unset
+	RetryBudget int
 }
 
-func writeSingleParameter(c chan WriteResult, client SSMClient, name string, value string, retryCount int) {
+// writer makes requests to parameter store within the limits of a WriteOptions
+type writer struct {
+	client  SSMClient
+	limiter *rate.Limiter
+	// budget is the number of retries left, shared by every parameter
+	budget atomic.Int64
+}
+
+func newWriter(client SSMClient, opts WriteOptions) *writer {
+	limit, burst := rate.Inf, 1
+	if opts.Rate > 0 {
+		limit, burst = rate.Limit(opts.Rate), int(math.Ceil(opts.Rate))
+	}
+	budget := opts.RetryBudget
+	if budget <= 0 {
+		budget = DefaultRetryBudget
+	}
+	w := &writer{client: client, limiter: rate.NewLimiter(limit, burst)}
+	w.budget.Store(int64(budget))
+	return w
+}
+
+// backoff is the exponential backoff with jitter before a retry, capped at maxBackoff
+func backoff(retryCount int) time.Duration {
+	r := math.Pow(2, float64(retryCount)) * (1 + rand.Float64())
+	// compare before converting, so large retry counts can't overflow a Duration
+	if r >= float64(maxBackoff/time.Millisecond) {
+		return maxBackoff
+	}
+	return time.Duration(r) * time.Millisecond
+}
+
+// call makes a single request to parameter store within the writer's limits: it waits for the rate limiter,
+// and retries the request with backoff while it's throttled, until maxRetries or the shared retry budget runs out.
+// action describes the request in errors, like "writing /a/parameter". It returns how many times the request was retried.
+func (w *writer) call(ctx context.Context, action string, request func() error) (int, error) {
+	for retries := 0; ; retries++ {
+		// once the context is done, we don't start any more requests
+		if err := w.wait(ctx); err != nil {
+			return retries, err
+		}
+		err := request()
+		if err == nil {
+			return retries, nil
+		}
+		var throttlingErr *types.ThrottlingException
+		if !errors.As(err, &throttlingErr) {
+			return retries, classify(err)
+		}
+		if retries >= maxRetries {
+			return retries, fmt.Errorf("%w: retry limit reached %s", ErrThrottled, action)
+		}
+		if w.budget.Add(-1) < 0 {
+			return retries, fmt.Errorf("%w: retry budget spent before %s", ErrThrottled, action)
+		}
+		// wait before retrying, cut short if the context is done
+		select {
+		case <-time.After(backoff(retries)):
+		case <-ctx.Done():
+			return retries, classify(ctx.Err())
+		}
+	}
+}
+
+// wait takes a token from the rate limiter, failing once the context is done
+// or would be done before a token is available
+func (w *writer) wait(ctx context.Context) error {
+	if err := w.limiter.Wait(ctx); err != nil {
+		if ctx.Err() != nil {
+			return classify(ctx.Err())
+		}
+		return classify(context.DeadlineExceeded)
+	}
+	return nil
+}
+
+func (w *writer) writeSingleParameter(ctx context.Context, name string, parameter Parameter) WriteResult {
 	overwrite := true
-	valueType := types.ParameterTypeSecureString
-	keyID := "alias/aws/ssm"
 	input := ssm.PutParameterInput{
-		KeyId:     &keyID,
 		Name:      &name,
 		Overwrite: &overwrite,
-		Type:      valueType,
-		Value:     &value,
+		Type:      parameter.Type,
+		Value:     &parameter.Value,
 	}
-	_, err := client.PutParameter(context.TODO(), &input)
-	if err != nil {
-		var throttlingErr *types.ThrottlingException
-		if errors.As(err, &throttlingErr) {
-			if retryCount < 100 {
-				// Introduce exponential backoff with jitter
-				r := math.Pow(2, float64(retryCount)) * (1 + rand.Float64())
-				time.Sleep(time.Duration(r) * time.Millisecond)
-				writeSingleParameter(c, client, name, value, retryCount+1)
-			} else {
-				c <- WriteResult{
-					Name:  name,
-					Error: errors.New("throttle retry limit reached for " + name),
-				}
-				return
-			}
-		} else {
-			c <- WriteResult{
-				Name:  name,
-				Error: err,
-			}
-			return
-		}
-	} else {
-		c <- WriteResult{
-			Name:  name,
-			Error: nil,
+	if input.Type == "" {
+		input.Type = types.ParameterTypeSecureString
+	}
+	// only SecureString parameters are encrypted, so only they take a key
+	if input.Type == types.ParameterTypeSecureString {
+		input.KeyId = aws.String(DefaultKeyID)
+		if parameter.KeyID != "" {
+			input.KeyId = &parameter.KeyID
 		}
-		return
 	}
+	if parameter.Description != "" {
+		input.Description = &parameter.Description
+	}
+	retries, err := w.call(ctx, "writing "+name, func() error {
+		_, err := w.client.PutParameter(ctx, &input)
+		return err
+	})
+	return WriteResult{Name: name, Error: err, Retries: retries}
 }
 
-// WriteToParameterStore writes given parameters to a given parameter store path
-func WriteToParameterStore(parameters map[string]string, path util.ParameterStorePath, timeout time.Duration, client SSMClient) error {
+// WriteToParameterStore writes given parameters to a given parameter store path,
+// a few at a time and no faster than the options allow.
+// A failed write doesn't stop the others: it returns one result per parameter, sorted by name,
+// along with an error summarizing the failures if there were any.
+func WriteToParameterStore(ctx context.Context, parameters map[string]Parameter, path util.ParameterStorePath, client SSMClient, opts WriteOptions) ([]WriteResult, error) {
+	results := make([]WriteResult, 0, len(parameters))
+	if len(parameters) == 0 {
+		return results, nil
+	}
 	if client == nil {
-		client = getSSMClient()
+		c, err := NewSSMClient(ctx)
+		if err != nil {
+			return results, err
+		}
+		client = c
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
 	}
+	w := newWriter(client, opts)
 
-	// the jobs channel will receive messages from successful parameter store writes
+	// a fixed pool of workers takes names off the names channel, and reports
+	// back on the jobs channel. When throttled, parameter writes wait, then retry.
+	names := make(chan string, len(parameters))
 	jobs := make(chan WriteResult, len(parameters))
-	for key, value := range parameters {
-		name := path.String() + key
-		// we pass the jobs channel into the asynchronous write function to receive
-		// success messages. When throttled, parameter writes wait, then retry.
-		go writeSingleParameter(jobs, client, name, value, 0)
-	}
-
-	// we keep track of the parameter store writes with results
-	results := make([]WriteResult, 0)
-	// the done channel will receive a message once all writes are complete
-	done := make(chan bool)
-	// this closure collects messages from the jobs channel: once it has enough
-	// (meaning all writes are successful or one has failed), it sends a message on the done channel
-	go func() {
-		for result := range jobs {
-			results = append(results, result)
-			if len(results) == len(parameters) || result.Error != nil {
-				done <- true
-			}
-		}
-	}()
-
-	// we let two channels race: after 1 minute, the channel from time.After wins,
-	// and we error out
-	select {
-	case <-done:
-		// if any results came back with errors, return the first error
-		for _, result := range results {
-			if result.Error != nil {
-				return result.Error
+	for key := range parameters {
+		names <- path.String() + key
+	}
+	close(names)
+	for i := 0; i < min(concurrency, len(parameters)); i++ {
+		go func() {
+			for name := range names {
+				jobs <- w.writeSingleParameter(ctx, name, parameters[strings.TrimPrefix(name, path.String())])
 			}
-		}
-		return nil
-	case <-time.After(timeout):
-		return errors.New("timeout")
+		}()
 	}
-}
 
-// determineParameterDelta determines the parameters that are present in parameter store, but missing locally
-func determineParameterDelta(parameters map[string]string, ssmParams map[string]string) []string {
-	parameterDelta := make([]string, 0)
-	for ssmParam := range ssmParams {
-		if _, ok := parameters[ssmParam]; !ok {
-			parameterDelta = append(parameterDelta, ssmParam)
-		}
+	// we wait for every write to report back, so no write is still in flight
+	// once we return, and we know exactly which parameters were written
+	for range parameters {
+		results = append(results, <-jobs)
 	}
-	return parameterDelta
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results, writeError(results)
 }
 
-// promptUserDeltaWarning prompt the user with a warning based on the delta of parameters in file vs ssm, and return approval
-func promptUserDeltaWarning(parameters []string, path util.ParameterStorePath) (bool, error) {
-	red := color.New(color.FgRed).SprintFunc()
-	green := color.New(color.FgGreen).SprintFunc()
-	fmt.Println("The following are not present in the file, but are in parameter store:")
-	for _, parameter := range parameters {
-		fullParameterPath := fmt.Sprintf("%s%s\n", path.String(), parameter)
-		fmt.Print(red(fullParameterPath))
+// writeError summarizes the failed writes among results, wrapping the first failure
+// so callers can still check its class with errors.Is. It is nil if every write succeeded.
+func writeError(results []WriteResult) error {
+	var first error
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			if first == nil {
+				first = result.Error
+			}
+			failed++
+		}
 	}
-	fmt.Printf("Are you sure you'd like to %s these parameters?\nType %s to proceed:\n", red("delete"), green("yes"))
-	reader := bufio.NewReader(os.Stdin)
-	text, err := reader.ReadString('\n')
-	if err != nil {
-		return false, err
+	if first == nil {
+		return nil
 	}
-	approval := strings.TrimSpace(text) == "yes"
-	return approval, nil
+	return fmt.Errorf("%d of %d parameters failed to write: %w", failed, len(results), first)
 }
 
 // find returns the index and presence of a value in a slice and returns -1, false if it's not there.
@@ -224,110 +481,241 @@ func findStringInSlice(slice []string, val string) (int, bool) {
 	return find(slice, val)
 }
 
-// deleteFromParameterStore deletes parameters at a given path from parameter store
-func deleteFromParameterStore(parameters []string, path util.ParameterStorePath, client SSMClient) (deletedParams []string, err error) {
-	deletedParams = []string{}
+// DeleteResult is the result of deleting a single parameter - successful if Error is nil
+type DeleteResult struct {
+	Name  string
+	Error error
+	// Retries is how many times the delete was retried after being throttled
+	Retries int
+}
 
-	fullPathParameters := make([]string, len(parameters))
-	for idx, parameter := range parameters {
-		fullPathParameter := fmt.Sprintf("%s%s", path.String(), parameter)
-		fullPathParameters[idx] = fullPathParameter
+// deleteBatchSize is the most names DeleteParameters takes at once
+const deleteBatchSize = 10
+
+// DeleteFromParameterStore deletes parameters at a given path from parameter store, in batches,
+// no faster than the options allow and retrying throttled batches like WriteToParameterStore.
+// A failed batch doesn't stop the others: it returns one result per parameter, sorted by name,
+// along with an error summarizing the failures if there were any.
+func DeleteFromParameterStore(ctx context.Context, parameters []string, path util.ParameterStorePath, client SSMClient, opts WriteOptions) ([]DeleteResult, error) {
+	results := make([]DeleteResult, 0, len(parameters))
+	w := newWriter(client, opts)
+	for start := 0; start < len(parameters); start += deleteBatchSize {
+		batch := parameters[start:min(start+deleteBatchSize, len(parameters))]
+		names := make([]string, len(batch))
+		for i, parameter := range batch {
+			names[i] = path.String() + parameter
+		}
+		results = append(results, w.deleteBatch(ctx, names)...)
 	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results, deleteError(results)
+}
 
-	var chunkSize int
-	var chunkedParameters [][]string
-
-	for {
-		if len(fullPathParameters) == 0 {
-			break
+// deleteBatch deletes up to deleteBatchSize parameters with a single call, retrying while throttled
+func (w *writer) deleteBatch(ctx context.Context, names []string) []DeleteResult {
+	results := make([]DeleteResult, len(names))
+	fail := func(err error, retries int) []DeleteResult {
+		for i, name := range names {
+			results[i] = DeleteResult{Name: name, Error: err, Retries: retries}
 		}
-
-		if len(fullPathParameters) < 10 {
-			chunkSize = len(fullPathParameters)
-		} else {
-			chunkSize = 10
+		return results
+	}
+	input := ssm.DeleteParametersInput{Names: names}
+	var output *ssm.DeleteParametersOutput
+	retries, err := w.call(ctx, "deleting parameters", func() error {
+		var err error
+		output, err = w.client.DeleteParameters(ctx, &input)
+		return err
+	})
+	if err != nil {
+		return fail(err, retries)
+	}
+	if output == nil {
+		return fail(errors.New("parameter store didn't say which parameters it deleted"), retries)
+	}
+	for i, name := range names {
+		results[i] = DeleteResult{Name: name, Retries: retries}
+		if _, invalid := findStringInSlice(output.InvalidParameters, name); invalid {
+			results[i].Error = fmt.Errorf("%w: %s", ErrNotFound, name)
+		} else if _, deleted := findStringInSlice(output.DeletedParameters, name); !deleted {
+			results[i].Error = fmt.Errorf("parameter store didn't report deleting %s", name)
 		}
-
-		chunkedParameters = append(chunkedParameters, fullPathParameters[0:chunkSize])
-		fullPathParameters = fullPathParameters[chunkSize:]
 	}
+	return results
+}
 
-	for _, params := range chunkedParameters {
-		deleteParametersInput := ssm.DeleteParametersInput{
-			Names: params,
+// deleteError summarizes the failed deletes among results, like writeError does for writes
+func deleteError(results []DeleteResult) error {
+	var first error
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			if first == nil {
+				first = result.Error
+			}
+			failed++
 		}
+	}
+	if first == nil {
+		return nil
+	}
+	return fmt.Errorf("%d of %d parameters failed to delete: %w", failed, len(results), first)
+}
 
-		output, err := client.DeleteParameters(context.TODO(), &deleteParametersInput)
+// ReadFile reads a json, yaml (or yml) or env file of key-value pairs.
+// An empty format picks one from the file's extension, falling back to json.
+// Keys written with the extended schema only give their value.
+func ReadFile(filename string, format string) (map[string]string, error) {
+	parameters, err := ReadParameterFile(filename, format)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(parameters))
+	for k, parameter := range parameters {
+		values[k] = parameter.Value
+	}
+	return values, nil
+}
 
+// ReadParameterFile reads a json, yaml (or yml) or env file of parameters.
+// In json and yaml files, each key holds either a plain value, or an object in the extended schema:
+// {"value": "...", "type": "String", "description": "...", "kms_key_id": "..."}. Keys without a type are SecureStrings.
+// An empty format picks one from the file's extension, falling back to json.
+func ReadParameterFile(filename string, format string) (map[string]Parameter, error) {
+	if format == "" {
+		format = detectFormat(filename)
+	}
+	var parse func([]byte) (map[string]fileParameter, error)
+	switch format {
+	case "json":
+		parse = parseJSON
+	case "yaml", "yml":
+		parse = parseYAML
+	case "env":
+		parse = parseEnv
+	default:
+		return nil, fmt.Errorf("%w: unknown input format %s (json, yaml, env allowed)", ErrInvalidFile, format)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	raw, err := parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error reading %s: %w", ErrInvalidFile, filename, err)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("%w: error reading %s: it's empty", ErrInvalidFile, filename)
+	}
+	parameters := make(map[string]Parameter, len(raw))
+	for k, r := range raw {
+		parameter, err := r.parameter()
 		if err != nil {
-			fmt.Println(err)
+			return nil, fmt.Errorf("%w: error reading %s: key %s: %w", ErrInvalidFile, filename, k, err)
 		}
+		parameters[k] = parameter
+	}
+	return parameters, nil
+}
 
-		if len(output.DeletedParameters) != len(params) {
-			fmt.Println("Some parameters failed to delete:")
-			for _, parameter := range params {
-				_, found := findStringInSlice(output.DeletedParameters, parameter)
-				if !found {
-					fmt.Println(parameter)
-				}
-			}
-		}
+// detectFormat returns the input format for a file's extension
+func detectFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".env":
+		return "env"
+	}
+	// dotenv files are often named .env, or .env.production, without an extension of their own
+	if strings.HasPrefix(filepath.Base(filename), ".env") {
+		return "env"
+	}
+	return "json"
+}
 
-		if len(output.InvalidParameters) != 0 {
-			fmt.Println("Some parameters failed to delete due to being invalid:")
-			for _, invalidParameter := range output.InvalidParameters {
-				fmt.Println(invalidParameter)
-			}
-		}
+// fileParameter is a key read from a file, before it's checked
+type fileParameter struct {
+	Value       *string `json:"value" yaml:"value"`
+	Type        string  `json:"type" yaml:"type"`
+	Description string  `json:"description" yaml:"description"`
+	KeyID       string  `json:"kms_key_id" yaml:"kms_key_id"`
+}
 
-		for _, deletedParam := range output.DeletedParameters {
-			deletedParams = append(deletedParams, deletedParam)
-		}
+// UnmarshalJSON accepts a plain string value, or an object in the extended schema
+func (f *fileParameter) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		f.Value = &value
+		return nil
+	}
+	type extended fileParameter
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode((*extended)(f)); err != nil {
+		return errors.New("each key should hold a string, or an object with a value, type, description and kms_key_id")
 	}
+	return nil
+}
 
-	return deletedParams, err
+// UnmarshalYAML accepts a plain scalar value, or a mapping in the extended schema
+func (f *fileParameter) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		f.Value = &value
+		return nil
+	}
+	type extended fileParameter
+	if err := unmarshal((*extended)(f)); err != nil {
+		return errors.New("each key should hold a string, or a mapping with a value, type, description and kms_key_id")
+	}
+	return nil
 }
 
-// DeleteDeltaFromParameterStore deletes the parameters that exist in parameter store, but not in the parameters variable
-func DeleteDeltaFromParameterStore(parameters map[string]string, path util.ParameterStorePath, autoApprove bool, client SSMClient) ([]string, error) {
-	if client == nil {
-		client = getSSMClient()
+// parameter checks a key read from a file
+func (f fileParameter) parameter() (Parameter, error) {
+	if f.Value == nil {
+		return Parameter{}, errors.New("missing value")
 	}
-	ssmParams := ReadFromParameterStore(path, client)
-	parameterDelta := determineParameterDelta(parameters, ssmParams)
-	if len(parameterDelta) == 0 {
-		return []string{}, nil
+	parameterType := types.ParameterType(f.Type)
+	if parameterType != "" && !slices.Contains(parameterType.Values(), parameterType) {
+		return Parameter{}, fmt.Errorf("unknown type %s (String, StringList, SecureString allowed)", f.Type)
 	}
-	if !autoApprove {
-		approved, err := promptUserDeltaWarning(parameterDelta, path)
-		if err != nil {
-			return []string{}, err
-		}
-		if !approved {
-			return []string{}, nil
+	parameter := Parameter{Value: *f.Value, Type: parameterType, Description: f.Description, KeyID: f.KeyID}
+	if parameter.KeyID != "" && !parameter.Secure() {
+		return Parameter{}, fmt.Errorf("kms_key_id is only allowed for SecureString parameters, not %s", f.Type)
+	}
+	return parameter, nil
+}
+
+func parseJSON(content []byte) (map[string]fileParameter, error) {
+	var output map[string]fileParameter
+	if err := json.Unmarshal(content, &output); err != nil {
+		if _, ok := err.(*json.SyntaxError); ok {
+			return nil, errors.New("check that it's valid json")
 		}
+		return nil, err
 	}
-	return deleteFromParameterStore(parameterDelta, path, client)
+	return output, nil
 }
 
-// ReadJSONFile reads a json file of key-value pairs
-func ReadJSONFile(filepath string) map[string]string {
-	content, err := ioutil.ReadFile(filepath)
+func parseYAML(content []byte) (map[string]fileParameter, error) {
+	var output map[string]fileParameter
+	if err := yaml.UnmarshalStrict(content, &output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func parseEnv(content []byte) (map[string]fileParameter, error) {
+	values, err := env.Unmarshal(string(content))
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	var output map[string]string
-	if err := json.Unmarshal(content, &output); err != nil {
-		switch err := err.(type) {
-		case *json.SyntaxError:
-			log.Fatal("error reading " + filepath + ": check that it's valid json")
-		case *json.UnmarshalTypeError:
-			log.Fatal("error reading " + filepath + ": it should contain only string key/value pairs")
-
-		default:
-			fmt.Println(reflect.TypeOf(err))
-			log.Fatal(err)
-		}
+	output := make(map[string]fileParameter, len(values))
+	for k, v := range values {
+		output[k] = fileParameter{Value: &v}
 	}
-	return output
+	return output, nil
 }
@@ -3,13 +3,16 @@ package io
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/smithy-go"
 	"github.com/pbs/gorson/internal/gorson/util"
 )
 
@@ -34,7 +37,9 @@ type mockedPutParameter struct {
 }
 
 type mockedGetParameter struct {
-	retVal mockedGetParametersByPathReturnPair
+	retVal         mockedGetParametersByPathReturnPair
+	describeRetVal ssm.DescribeParametersOutput
+	historyRetVals map[string][]types.ParameterHistory
 }
 
 type mockedDeleteDelta struct {
@@ -62,10 +67,18 @@ func (m mockedPutParameter) DeleteParameters(ctx context.Context, input *ssm.Del
 	return nil, errors.New("not implemented")
 }
 
+func (m mockedGetParameter) DescribeParameters(ctx context.Context, input *ssm.DescribeParametersInput, opts ...func(*ssm.Options)) (*ssm.DescribeParametersOutput, error) {
+	return &m.describeRetVal, nil
+}
+
 func (m mockedGetParameter) GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput, opts ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
 	return &m.retVal.Resp, m.retVal.Err
 }
 
+func (m mockedGetParameter) GetParameterHistory(ctx context.Context, input *ssm.GetParameterHistoryInput, opts ...func(*ssm.Options)) (*ssm.GetParameterHistoryOutput, error) {
+	return &ssm.GetParameterHistoryOutput{Parameters: m.historyRetVals[*input.Name]}, nil
+}
+
 func (m mockedGetParameter) PutParameter(ctx context.Context, input *ssm.PutParameterInput, opts ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
 	return nil, errors.New("not implemented")
 }
@@ -114,14 +127,6 @@ type ReadFromParameterStoreTestCase struct {
 	Expected        map[string]string
 }
 
-type DeleteDeltaFromParameterStoreTestCase struct {
-	FileParams         map[string]string
-	GetParamsRetVal    mockedGetParametersByPathReturnPair
-	DeleteParamsRetVal mockedDeleteParametersReturnPair
-	DeleteSuccessful   bool
-	Expected           []string
-}
-
 func TestReadFromParameterStore(t *testing.T) {
 	cases := []ReadFromParameterStoreTestCase{
 		{
@@ -145,13 +150,121 @@ func TestReadFromParameterStore(t *testing.T) {
 	path := util.NewParameterStorePath("/path/parameter")
 
 	for i, c := range cases {
-		parameters := ReadFromParameterStore(*path, &mockedGetParameter{retVal: c.GetParamsRetVal})
+		parameters, err := ReadFromParameterStore(context.Background(), *path, &mockedGetParameter{retVal: c.GetParamsRetVal})
+		if err != nil {
+			t.Fatalf("%v unexpected error %v", i, err)
+		}
 		if !reflect.DeepEqual(c.Expected, parameters) {
 			t.Fatalf("%v expected %v, got %v", i, c.Expected, parameters)
 		}
 	}
 }
 
+func TestReadParametersFromParameterStore(t *testing.T) {
+	retVal := mockedGetParametersByPathReturnPair{
+		Resp: ssm.GetParametersByPathOutput{
+			Parameters: []types.Parameter{
+				{
+					Name:  aws.String("/path/parameter/secure"),
+					Value: aws.String("value"),
+					Type:  types.ParameterTypeSecureString,
+				},
+				{
+					Name:  aws.String("/path/parameter/plain"),
+					Value: aws.String("value"),
+					Type:  types.ParameterTypeString,
+				},
+			},
+		},
+		Err: nil,
+	}
+	expected := map[string]Parameter{
+		"secure": {Value: "value", Type: types.ParameterTypeSecureString},
+		"plain":  {Value: "value", Type: types.ParameterTypeString},
+	}
+
+	path := util.NewParameterStorePath("/path/parameter")
+	parameters, err := ReadParametersFromParameterStore(context.Background(), *path, &mockedGetParameter{retVal: retVal})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(expected, parameters) {
+		t.Fatalf("expected %v, got %v", expected, parameters)
+	}
+}
+
+func TestReadParametersWithMetadata(t *testing.T) {
+	m := &mockedGetParameter{
+		retVal: mockedGetParametersByPathReturnPair{
+			Resp: ssm.GetParametersByPathOutput{
+				Parameters: []types.Parameter{
+					{Name: aws.String("/path/parameter/plain"), Value: aws.String("value"), Type: types.ParameterTypeString},
+					{Name: aws.String("/path/parameter/secure"), Value: aws.String("value"), Type: types.ParameterTypeSecureString},
+				},
+			},
+		},
+		describeRetVal: ssm.DescribeParametersOutput{
+			Parameters: []types.ParameterMetadata{
+				{Name: aws.String("/path/parameter/plain"), Type: types.ParameterTypeString, Description: aws.String("a plain string")},
+				{Name: aws.String("/path/parameter/secure"), Type: types.ParameterTypeSecureString, KeyId: aws.String("alias/app")},
+			},
+		},
+	}
+	expected := map[string]Parameter{
+		"plain":  {Value: "value", Type: types.ParameterTypeString, Description: "a plain string"},
+		"secure": {Value: "value", Type: types.ParameterTypeSecureString, KeyID: "alias/app"},
+	}
+
+	path := util.NewParameterStorePath("/path/parameter")
+	parameters, err := ReadParametersWithMetadata(context.Background(), *path, false, m)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(expected, parameters) {
+		t.Fatalf("expected %v, got %v", expected, parameters)
+	}
+
+	// a client with only the SSMClient methods can still read values, but not descriptions
+	basic := struct{ SSMClient }{m}
+	if _, err := ReadFromParameterStore(context.Background(), *path, basic); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := ReadParametersWithMetadata(context.Background(), *path, false, basic); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected %v, got %v", errors.ErrUnsupported, err)
+	}
+}
+
+func TestReadFromParameterStoreRecursive(t *testing.T) {
+	retVal := mockedGetParametersByPathReturnPair{
+		Resp: ssm.GetParametersByPathOutput{
+			Parameters: []types.Parameter{
+				{
+					Name:  aws.String("/path/parameter/db/host"),
+					Value: aws.String("db.example.com"),
+				},
+				{
+					Name:  aws.String("/path/parameter/cache/host"),
+					Value: aws.String("cache.example.com"),
+				},
+			},
+		},
+		Err: nil,
+	}
+	expected := map[string]string{
+		"db/host":    "db.example.com",
+		"cache/host": "cache.example.com",
+	}
+
+	path := util.NewParameterStorePath("/path/parameter")
+	parameters, err := ReadFromParameterStoreRecursive(context.Background(), *path, &mockedGetParameter{retVal: retVal})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(expected, parameters) {
+		t.Fatalf("expected %v, got %v", expected, parameters)
+	}
+}
+
 func TestWriteSingleParameter(t *testing.T) {
 	cases := []WriteSingleParamTestCase{
 		// happy case: no throttling, no error. smooth sailing!
@@ -203,10 +316,9 @@ func TestWriteSingleParameter(t *testing.T) {
 	}
 
 	for i, c := range cases {
-		outputChannel := make(chan WriteResult, 1)
 		callCount := 0
-		writeSingleParameter(outputChannel, &mockedPutParameter{retVals: c.PutParameterReturnRetVals, callCount: &callCount}, "key", "value", 0)
-		result := <-outputChannel
+		w := newWriter(&mockedPutParameter{retVals: c.PutParameterReturnRetVals, callCount: &callCount}, WriteOptions{})
+		result := w.writeSingleParameter(context.Background(), "key", Parameter{Value: "value"})
 		if c.Expected != nil {
 			if result.Error == nil {
 				t.Fatalf("%d expected %v, got %v", i, c.Expected, result.Error)
@@ -222,6 +334,41 @@ func TestWriteSingleParameter(t *testing.T) {
 	}
 }
 
+// mockedRecordingPutParameter keeps the input of every write
+type mockedRecordingPutParameter struct {
+	mockedPutParameter
+	inputs []*ssm.PutParameterInput
+}
+
+func (m *mockedRecordingPutParameter) PutParameter(ctx context.Context, in *ssm.PutParameterInput, opts ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	m.inputs = append(m.inputs, in)
+	return &ssm.PutParameterOutput{}, nil
+}
+
+func TestWriteSingleParameterTypes(t *testing.T) {
+	cases := []struct {
+		Parameter   Parameter
+		Type        types.ParameterType
+		KeyID       *string
+		Description *string
+	}{
+		{Parameter{Value: "value"}, types.ParameterTypeSecureString, aws.String("alias/aws/ssm"), nil},
+		{Parameter{Value: "value", Type: types.ParameterTypeString, Description: "plain"}, types.ParameterTypeString, nil, aws.String("plain")},
+		{Parameter{Value: "a,b", Type: types.ParameterTypeStringList}, types.ParameterTypeStringList, nil, nil},
+		{Parameter{Value: "value", KeyID: "alias/app"}, types.ParameterTypeSecureString, aws.String("alias/app"), nil},
+	}
+	for i, c := range cases {
+		m := &mockedRecordingPutParameter{}
+		if result := newWriter(m, WriteOptions{}).writeSingleParameter(context.Background(), "key", c.Parameter); result.Error != nil {
+			t.Fatalf("%d unexpected error %v", i, result.Error)
+		}
+		input := m.inputs[0]
+		if input.Type != c.Type || !reflect.DeepEqual(input.KeyId, c.KeyID) || !reflect.DeepEqual(input.Description, c.Description) {
+			t.Fatalf("%d expected %s with key %v and description %v, got %+v", i, c.Type, c.KeyID, c.Description, input)
+		}
+	}
+}
+
 func TestWriteToParameterStore(t *testing.T) {
 	cases := []WriteToParameterStoreTestCase{
 		// Plenty of time to pretend to put parameters
@@ -250,417 +397,461 @@ func TestWriteToParameterStore(t *testing.T) {
 				},
 			},
 			Timeout:  time.Duration(0) * time.Minute,
-			Expected: errors.New("timeout"),
+			Expected: ErrTimeout,
 		},
 	}
 
 	path := util.NewParameterStorePath("/path/")
+
+	// Nothing to write finishes immediately instead of waiting out the timeout
+	if _, err := WriteToParameterStore(context.Background(), map[string]Parameter{}, *path, nil, WriteOptions{}); err != nil {
+		t.Fatalf("expected no error writing no parameters, got %v", err)
+	}
+
 	for i, c := range cases {
 		callCount := 0
-		err := WriteToParameterStore(map[string]string{"path": "value"}, *path, c.Timeout, &mockedPutParameter{retVals: c.PutParameterReturnRetVals, callCount: &callCount})
+		ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+		results, err := WriteToParameterStore(ctx, map[string]Parameter{"path": {Value: "value"}}, *path, &mockedPutParameter{retVals: c.PutParameterReturnRetVals, callCount: &callCount}, WriteOptions{})
+		cancel()
+		if len(results) != 1 || results[0].Name != "/path/path" {
+			t.Fatalf("%d expected a result for /path/path, got %v", i, results)
+		}
 		if c.Expected != nil {
-			if err == nil {
+			if !errors.Is(err, c.Expected) || !errors.Is(results[0].Error, c.Expected) {
 				t.Fatalf("%d expected %v, got %v", i, c.Expected, err)
 			}
-			if err.Error() != c.Expected.Error() {
-				t.Fatalf("%d expected %v, got %v", i, c.Expected, err)
+			if callCount != 0 {
+				t.Fatalf("%d expected no writes after the deadline, got %d", i, callCount)
 			}
 		} else {
-			if err != nil {
+			if err != nil || results[0].Error != nil {
 				t.Fatalf("%d expected %v, got %v", i, c.Expected, err)
 			}
 		}
 	}
+
+	// Cancelling stops writes, and the caller learns what happened to every parameter
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	callCount := 0
+	results, err := WriteToParameterStore(ctx, map[string]Parameter{"a": {Value: "1"}, "b": {Value: "2"}}, *path, &mockedPutParameter{callCount: &callCount}, WriteOptions{})
+	if !errors.Is(err, context.Canceled) || len(results) != 2 || callCount != 0 {
+		t.Fatalf("expected cancellation with nothing written, got %v and %v", err, results)
+	}
 }
 
-func TestDeleteDeltaFromParameterStore(t *testing.T) {
-	cases := []DeleteDeltaFromParameterStoreTestCase{
-		// Nothing to delete
-		{
-			FileParams: map[string]string{
-				"paramOne": "valueOne",
-				"paramTwo": "valueTwo",
-			},
-			GetParamsRetVal: mockedGetParametersByPathReturnPair{
-				Resp: ssm.GetParametersByPathOutput{
-					Parameters: []types.Parameter{
-						{
-							Name:  aws.String("paramOne"),
-							Value: aws.String("valueOne"),
-						},
-						{
-							Name:  aws.String("paramTwo"),
-							Value: aws.String("valueTwo"),
-						},
-					},
-				},
-				Err: nil,
-			},
-			DeleteSuccessful: true,
-			Expected:         []string{},
-		},
-		// Still nothing to delete. Theoretically impossible, as param three should have been pushed
+// mockedPartialPutParameter fails writes to the names in fail, and succeeds otherwise
+type mockedPartialPutParameter struct {
+	mockedPutParameter
+	fail map[string]error
+}
+
+func (m mockedPartialPutParameter) PutParameter(ctx context.Context, in *ssm.PutParameterInput, opts ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	return &ssm.PutParameterOutput{}, m.fail[*in.Name]
+}
+
+func TestWriteToParameterStorePartialFailure(t *testing.T) {
+	path := util.NewParameterStorePath("/path/")
+	denied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not allowed"}
+	m := mockedPartialPutParameter{fail: map[string]error{"/path/b": denied}}
+
+	// a failure doesn't cancel the other writes, and every parameter gets a result
+	results, err := WriteToParameterStore(context.Background(), map[string]Parameter{"a": {Value: "1"}, "b": {Value: "2"}, "c": {Value: "3"}}, *path, m, WriteOptions{Concurrency: 1})
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected %v, got %v", ErrAccessDenied, err)
+	}
+	if err.Error() != "1 of 3 parameters failed to write: access denied: api error AccessDeniedException: not allowed" {
+		t.Fatalf("unexpected error message %q", err)
+	}
+	names := make([]string, len(results))
+	for i, result := range results {
+		names[i] = result.Name
+		if (result.Error != nil) != (result.Name == "/path/b") {
+			t.Fatalf("unexpected result %v", result)
+		}
+	}
+	if expected := []string{"/path/a", "/path/b", "/path/c"}; !reflect.DeepEqual(expected, names) {
+		t.Fatalf("expected results for %v, got %v", expected, names)
+	}
+}
+
+// mockedScriptedDelete answers each DeleteParameters call with the next output and error in turn,
+// and records the names of every call
+type mockedScriptedDelete struct {
+	mockedDeleteDelta
+	outputs []*ssm.DeleteParametersOutput
+	errs    []error
+	calls   [][]string
+}
+
+func (m *mockedScriptedDelete) DeleteParameters(ctx context.Context, input *ssm.DeleteParametersInput, opts ...func(*ssm.Options)) (*ssm.DeleteParametersOutput, error) {
+	i := len(m.calls)
+	m.calls = append(m.calls, input.Names)
+	return m.outputs[i], m.errs[i]
+}
+
+func TestDeleteFromParameterStore(t *testing.T) {
+	path := util.NewParameterStorePath("/path/")
+	throttled := &types.ThrottlingException{Message: aws.String("slow down")}
+	denied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not allowed"}
+	deleted := &ssm.DeleteParametersOutput{DeletedParameters: []string{"/path/a", "/path/b"}}
+	cases := []struct {
+		name    string
+		outputs []*ssm.DeleteParametersOutput
+		errs    []error
+		failed  []string
+		err     error
+		retries int
+	}{
+		{"deleted", []*ssm.DeleteParametersOutput{deleted}, []error{nil}, []string{}, nil, 0},
+		{"retried when throttled", []*ssm.DeleteParametersOutput{nil, nil, deleted}, []error{throttled, throttled, nil}, []string{}, nil, 2},
+		{"every parameter fails with the api error", []*ssm.DeleteParametersOutput{nil}, []error{denied}, []string{"/path/a", "/path/b"}, ErrAccessDenied, 0},
+		{"a nil output fails without panicking", []*ssm.DeleteParametersOutput{nil}, []error{nil}, []string{"/path/a", "/path/b"}, nil, 0},
+		{"invalid parameters fail", []*ssm.DeleteParametersOutput{{DeletedParameters: []string{"/path/a"}, InvalidParameters: []string{"/path/b"}}}, []error{nil}, []string{"/path/b"}, ErrNotFound, 0},
+		{"unreported parameters fail", []*ssm.DeleteParametersOutput{{DeletedParameters: []string{"/path/b"}}}, []error{nil}, []string{"/path/a"}, nil, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := &mockedScriptedDelete{outputs: c.outputs, errs: c.errs}
+			results, err := DeleteFromParameterStore(context.Background(), []string{"b", "a"}, *path, m, WriteOptions{})
+			if (err != nil) != (len(c.failed) > 0) || (c.err != nil && !errors.Is(err, c.err)) {
+				t.Fatalf("expected %v failed with %v, got %v", c.failed, c.err, err)
+			}
+			if len(results) != 2 || results[0].Name != "/path/a" || results[1].Name != "/path/b" {
+				t.Fatalf("expected sorted results for both parameters, got %v", results)
+			}
+			failed := make([]string, 0)
+			for _, result := range results {
+				if result.Error != nil {
+					failed = append(failed, result.Name)
+				}
+				if result.Retries != c.retries {
+					t.Errorf("expected %d retries, got %d", c.retries, result.Retries)
+				}
+			}
+			if !reflect.DeepEqual(c.failed, failed) {
+				t.Errorf("expected %v to fail, got %v", c.failed, failed)
+			}
+		})
+	}
+}
+
+func TestDeleteFromParameterStoreBatches(t *testing.T) {
+	path := util.NewParameterStorePath("/path/")
+	parameters := make([]string, 25)
+	outputs := make([]*ssm.DeleteParametersOutput, 3)
+	errs := make([]error, 3)
+	for i := range parameters {
+		parameters[i] = fmt.Sprintf("p%02d", i)
+	}
+	for i := range outputs {
+		outputs[i] = &ssm.DeleteParametersOutput{}
+		for _, p := range parameters[i*10 : min(i*10+10, len(parameters))] {
+			outputs[i].DeletedParameters = append(outputs[i].DeletedParameters, path.String()+p)
+		}
+	}
+	m := &mockedScriptedDelete{outputs: outputs, errs: errs}
+	results, err := DeleteFromParameterStore(context.Background(), parameters, *path, m, WriteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.calls) != 3 || len(m.calls[0]) != 10 || len(m.calls[2]) != 5 {
+		t.Errorf("expected batches of 10, 10 and 5, got %v", m.calls)
+	}
+	if len(results) != 25 {
+		t.Errorf("expected 25 results, got %d", len(results))
+	}
+}
+
+// mockedThrottledPutParameter throttles every write, and records the most writes it saw in flight at once
+type mockedThrottledPutParameter struct {
+	mockedPutParameter
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	calls    int
+}
+
+func (m *mockedThrottledPutParameter) PutParameter(ctx context.Context, in *ssm.PutParameterInput, opts ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	m.mu.Lock()
+	m.calls++
+	m.inFlight++
+	m.peak = max(m.peak, m.inFlight)
+	m.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	m.mu.Lock()
+	m.inFlight--
+	m.mu.Unlock()
+	return nil, &types.ThrottlingException{Message: aws.String("slow it down")}
+}
+
+func TestWriteToParameterStoreLimits(t *testing.T) {
+	parameters := make(map[string]Parameter)
+	for i := 0; i < 20; i++ {
+		parameters[fmt.Sprintf("key%d", i)] = Parameter{Value: "value"}
+	}
+	path := util.NewParameterStorePath("/path/")
+
+	m := &mockedThrottledPutParameter{}
+	results, err := WriteToParameterStore(context.Background(), parameters, *path, m, WriteOptions{Concurrency: 3, RetryBudget: 5})
+	if !errors.Is(err, ErrThrottled) || len(results) != 20 {
+		t.Fatalf("expected throttling with a result per parameter, got %v and %v", err, results)
+	}
+	retries := 0
+	for _, result := range results {
+		retries += result.Retries
+	}
+	if retries > 5 {
+		t.Fatalf("expected at most 5 retries in total, got %d", retries)
+	}
+	if m.peak > 3 {
+		t.Fatalf("expected at most 3 writes in flight, got %d", m.peak)
+	}
+	// every parameter gets one attempt, then retries stop once the budget is spent
+	if m.calls != 20+5 {
+		t.Fatalf("expected the retry budget to bound calls, got %d", m.calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for retryCount := 0; retryCount < 100; retryCount++ {
+		if d := backoff(retryCount); d <= 0 || d > maxBackoff {
+			t.Fatalf("%d expected a backoff up to %v, got %v", retryCount, maxBackoff, d)
+		}
+	}
+}
+
+func TestReadFromParameterStoreErrors(t *testing.T) {
+	cases := []struct {
+		Err      error
+		Expected error
+	}{
 		{
-			FileParams: map[string]string{
-				"paramOne":   "valueOne",
-				"paramTwo":   "valueTwo",
-				"paramThree": "valueThree",
-			},
-			GetParamsRetVal: mockedGetParametersByPathReturnPair{
-				Resp: ssm.GetParametersByPathOutput{
-					Parameters: []types.Parameter{
-						{
-							Name:  aws.String("paramOne"),
-							Value: aws.String("valueOne"),
-						},
-						{
-							Name:  aws.String("paramTwo"),
-							Value: aws.String("valueTwo"),
-						},
-					},
-				},
-				Err: nil,
-			},
-			DeleteSuccessful: true,
-			Expected:         []string{},
+			Err:      &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not allowed"},
+			Expected: ErrAccessDenied,
 		},
-		// One thing to delete
 		{
-			FileParams: map[string]string{
-				"paramOne": "valueOne",
-			},
-			GetParamsRetVal: mockedGetParametersByPathReturnPair{
-				Resp: ssm.GetParametersByPathOutput{
-					Parameters: []types.Parameter{
-						{
-							Name:  aws.String("paramOne"),
-							Value: aws.String("valueOne"),
-						},
-						{
-							Name:  aws.String("paramTwo"),
-							Value: aws.String("valueTwo"),
-						},
-					},
-				},
-				Err: nil,
-			},
-			DeleteSuccessful: true,
-			Expected: []string{
-				"/path/paramTwo",
-			},
+			Err:      &types.ThrottlingException{Message: aws.String("slow it down")},
+			Expected: ErrThrottled,
 		},
-		// Other thing to delete
 		{
-			FileParams: map[string]string{
-				"paramTwo": "valueTwo",
-			},
-			GetParamsRetVal: mockedGetParametersByPathReturnPair{
-				Resp: ssm.GetParametersByPathOutput{
-					Parameters: []types.Parameter{
-						{
-							Name:  aws.String("paramOne"),
-							Value: aws.String("valueOne"),
-						},
-						{
-							Name:  aws.String("paramTwo"),
-							Value: aws.String("valueTwo"),
-						},
-					},
-				},
-				Err: nil,
-			},
-			DeleteSuccessful: true,
-			Expected: []string{
-				"/path/paramOne",
-			},
+			Err:      &types.ParameterNotFound{Message: aws.String("no such parameter")},
+			Expected: ErrNotFound,
 		},
-		// Two things to delete
-		{
-			FileParams: map[string]string{},
-			GetParamsRetVal: mockedGetParametersByPathReturnPair{
-				Resp: ssm.GetParametersByPathOutput{
-					Parameters: []types.Parameter{
-						{
-							Name:  aws.String("paramOne"),
-							Value: aws.String("valueOne"),
-						},
-						{
-							Name:  aws.String("paramTwo"),
-							Value: aws.String("valueTwo"),
-						},
-					},
+	}
+
+	path := util.NewParameterStorePath("/path/parameter")
+	for i, c := range cases {
+		retVal := mockedGetParametersByPathReturnPair{Err: c.Err}
+		_, err := ReadFromParameterStore(context.Background(), *path, &mockedGetParameter{retVal: retVal})
+		if !errors.Is(err, c.Expected) {
+			t.Fatalf("%d expected %v, got %v", i, c.Expected, err)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	expected := map[string]string{
+		"alpha": "the_alpha_value",
+		"beta":  "the_beta_value",
+		"delta": "the_delta_value",
+	}
+	cases := []struct {
+		filepath string
+		format   string
+	}{
+		{"../../../fixtures/useful-parameters.json", ""},
+		{"../../../fixtures/useful-parameters.yaml", ""},
+		{"../../../fixtures/useful-parameters.env", ""},
+		{"../../../fixtures/useful-parameters.env", "env"},
+	}
+	for _, c := range cases {
+		parameters, err := ReadFile(c.filepath, c.format)
+		if err != nil {
+			t.Fatalf("%s unexpected error %v", c.filepath, err)
+		}
+		if !reflect.DeepEqual(expected, parameters) {
+			t.Fatalf("%s expected %v, got %v", c.filepath, expected, parameters)
+		}
+	}
+
+	for _, c := range []struct {
+		filepath string
+		format   string
+	}{
+		{"../../../fixtures/nested.yaml", ""},
+		{"../../../fixtures/useful-parameters.json", "env"},
+		{"../../../fixtures/useful-parameters.json", "toml"},
+		{"../../../fixtures/badly-formatted.json", ""},
+		{"../../../fixtures/empty-file.json", ""},
+		{"../../../fixtures/valid-nonsense.json", ""},
+		{"../../../fixtures/does-not-exist.json", ""},
+	} {
+		if _, err := ReadFile(c.filepath, c.format); !errors.Is(err, ErrInvalidFile) {
+			t.Fatalf("%s as %q expected %v, got %v", c.filepath, c.format, ErrInvalidFile, err)
+		}
+	}
+
+	for filename, expected := range map[string]string{
+		"values.yml":       "yaml",
+		"values.YAML":      "yaml",
+		".env":             "env",
+		"dir/.env.staging": "env",
+		"values.json":      "json",
+	} {
+		if format := detectFormat(filename); format != expected {
+			t.Errorf("%s expected %s, got %s", filename, expected, format)
+		}
+	}
+}
+
+func TestReadParameterFile(t *testing.T) {
+	expected := map[string]Parameter{
+		"alpha": {Value: "the_alpha_value"},
+		"beta":  {Value: "the_beta_value", Type: types.ParameterTypeString, Description: "a plain string"},
+		"delta": {Value: "one,two,three", Type: types.ParameterTypeStringList},
+		"gamma": {Value: "the_gamma_value", KeyID: "alias/app"},
+	}
+	for _, filepath := range []string{
+		"../../../fixtures/extended-parameters.json",
+		"../../../fixtures/extended-parameters.yaml",
+	} {
+		parameters, err := ReadParameterFile(filepath, "")
+		if err != nil {
+			t.Fatalf("%s unexpected error %v", filepath, err)
+		}
+		if !reflect.DeepEqual(expected, parameters) {
+			t.Fatalf("%s expected %v, got %v", filepath, expected, parameters)
+		}
+	}
+
+	// files with the extended schema still give plain values
+	values, err := ReadFile("../../../fixtures/extended-parameters.json", "")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if values["beta"] != "the_beta_value" {
+		t.Fatalf("expected the value of beta, got %v", values)
+	}
+
+	for _, filepath := range []string{
+		"../../../fixtures/unknown-type.json",
+		"../../../fixtures/kms-key-on-string.json",
+		"../../../fixtures/valid-nonsense.json",
+	} {
+		if _, err := ReadParameterFile(filepath, ""); !errors.Is(err, ErrInvalidFile) {
+			t.Fatalf("%s expected %v, got %v", filepath, ErrInvalidFile, err)
+		}
+	}
+}
+
+func TestReadPathHistory(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	m := &mockedGetParameter{
+		retVal: mockedGetParametersByPathReturnPair{
+			Resp: ssm.GetParametersByPathOutput{
+				Parameters: []types.Parameter{
+					{Name: aws.String("/path/parameter/alpha"), Value: aws.String("two"), Type: types.ParameterTypeSecureString},
 				},
-				Err: nil,
-			},
-			DeleteSuccessful: true,
-			Expected: []string{
-				"/path/paramOne",
-				"/path/paramTwo",
 			},
 		},
-		// Twenty things to delete
-		{
-			FileParams: map[string]string{},
-			GetParamsRetVal: mockedGetParametersByPathReturnPair{
-				Resp: ssm.GetParametersByPathOutput{
-					Parameters: []types.Parameter{
-						{
-							Name:  aws.String("paramOne"),
-							Value: aws.String("valueOne"),
-						},
-						{
-							Name:  aws.String("paramTwo"),
-							Value: aws.String("valueTwo"),
-						},
-						{
-							Name:  aws.String("paramThree"),
-							Value: aws.String("valueThree"),
-						},
-						{
-							Name:  aws.String("paramFour"),
-							Value: aws.String("valueFour"),
-						},
-						{
-							Name:  aws.String("paramFive"),
-							Value: aws.String("valueFive"),
-						},
-						{
-							Name:  aws.String("paramSix"),
-							Value: aws.String("valueSix"),
-						},
-						{
-							Name:  aws.String("paramSeven"),
-							Value: aws.String("valueSeven"),
-						},
-						{
-							Name:  aws.String("paramEight"),
-							Value: aws.String("valueEight"),
-						},
-						{
-							Name:  aws.String("paramNine"),
-							Value: aws.String("valueNine"),
-						},
-						{
-							Name:  aws.String("paramTen"),
-							Value: aws.String("valueTen"),
-						},
-						{
-							Name:  aws.String("paramEleven"),
-							Value: aws.String("valueEleven"),
-						},
-						{
-							Name:  aws.String("paramTwelve"),
-							Value: aws.String("valueTwelve"),
-						},
-						{
-							Name:  aws.String("paramThirteen"),
-							Value: aws.String("valueThirteen"),
-						},
-						{
-							Name:  aws.String("paramFourteen"),
-							Value: aws.String("valueFourteen"),
-						},
-						{
-							Name:  aws.String("paramFifteen"),
-							Value: aws.String("valueFifteen"),
-						},
-						{
-							Name:  aws.String("paramSixteen"),
-							Value: aws.String("valueSixteen"),
-						},
-						{
-							Name:  aws.String("paramSeventeen"),
-							Value: aws.String("valueSeventeen"),
-						},
-						{
-							Name:  aws.String("paramEighteen"),
-							Value: aws.String("valueEighteen"),
-						},
-						{
-							Name:  aws.String("paramNineteen"),
-							Value: aws.String("valueNineteen"),
-						},
-						{
-							Name:  aws.String("paramTwenty"),
-							Value: aws.String("valueTwenty"),
-						},
-					},
-				},
-				Err: nil,
-			},
-			DeleteSuccessful: true,
-			Expected: []string{
-				"/path/paramOne",
-				"/path/paramTwo",
-				"/path/paramThree",
-				"/path/paramFour",
-				"/path/paramFive",
-				"/path/paramSix",
-				"/path/paramSeven",
-				"/path/paramEight",
-				"/path/paramNine",
-				"/path/paramTen",
-				"/path/paramEleven",
-				"/path/paramTwelve",
-				"/path/paramThirteen",
-				"/path/paramFourteen",
-				"/path/paramFifteen",
-				"/path/paramSixteen",
-				"/path/paramSeventeen",
-				"/path/paramEighteen",
-				"/path/paramNineteen",
-				"/path/paramTwenty",
+		historyRetVals: map[string][]types.ParameterHistory{
+			"/path/parameter/alpha": {
+				{Version: 2, Value: aws.String("two"), Type: types.ParameterTypeSecureString, KeyId: aws.String("alias/aws/ssm"), LastModifiedDate: &tuesday, LastModifiedUser: aws.String("bob")},
+				{Version: 1, Value: aws.String("one"), Type: types.ParameterTypeString, KeyId: aws.String("ignored"), LastModifiedDate: &monday, LastModifiedUser: aws.String("alice"), Labels: []string{"release"}},
 			},
 		},
-		// Twenty-two things to delete
-		{
-			FileParams: map[string]string{},
-			GetParamsRetVal: mockedGetParametersByPathReturnPair{
-				Resp: ssm.GetParametersByPathOutput{
-					Parameters: []types.Parameter{
-						{
-							Name:  aws.String("paramOne"),
-							Value: aws.String("valueOne"),
-						},
-						{
-							Name:  aws.String("paramTwo"),
-							Value: aws.String("valueTwo"),
-						},
-						{
-							Name:  aws.String("paramThree"),
-							Value: aws.String("valueThree"),
-						},
-						{
-							Name:  aws.String("paramFour"),
-							Value: aws.String("valueFour"),
-						},
-						{
-							Name:  aws.String("paramFive"),
-							Value: aws.String("valueFive"),
-						},
-						{
-							Name:  aws.String("paramSix"),
-							Value: aws.String("valueSix"),
-						},
-						{
-							Name:  aws.String("paramSeven"),
-							Value: aws.String("valueSeven"),
-						},
-						{
-							Name:  aws.String("paramEight"),
-							Value: aws.String("valueEight"),
-						},
-						{
-							Name:  aws.String("paramNine"),
-							Value: aws.String("valueNine"),
-						},
-						{
-							Name:  aws.String("paramTen"),
-							Value: aws.String("valueTen"),
-						},
-						{
-							Name:  aws.String("paramEleven"),
-							Value: aws.String("valueEleven"),
-						},
-						{
-							Name:  aws.String("paramTwelve"),
-							Value: aws.String("valueTwelve"),
-						},
-						{
-							Name:  aws.String("paramThirteen"),
-							Value: aws.String("valueThirteen"),
-						},
-						{
-							Name:  aws.String("paramFourteen"),
-							Value: aws.String("valueFourteen"),
-						},
-						{
-							Name:  aws.String("paramFifteen"),
-							Value: aws.String("valueFifteen"),
-						},
-						{
-							Name:  aws.String("paramSixteen"),
-							Value: aws.String("valueSixteen"),
-						},
-						{
-							Name:  aws.String("paramSeventeen"),
-							Value: aws.String("valueSeventeen"),
-						},
-						{
-							Name:  aws.String("paramEighteen"),
-							Value: aws.String("valueEighteen"),
-						},
-						{
-							Name:  aws.String("paramNineteen"),
-							Value: aws.String("valueNineteen"),
-						},
-						{
-							Name:  aws.String("paramTwenty"),
-							Value: aws.String("valueTwenty"),
-						},
-						{
-							Name:  aws.String("paramTwentyOne"),
-							Value: aws.String("valueTwentyOne"),
-						},
-						{
-							Name:  aws.String("paramTwentyTwo"),
-							Value: aws.String("valueTwentyTwo"),
-						},
-					},
-				},
-				Err: nil,
-			},
-			DeleteSuccessful: true,
-			Expected: []string{
-				"/path/paramOne",
-				"/path/paramTwo",
-				"/path/paramThree",
-				"/path/paramFour",
-				"/path/paramFive",
-				"/path/paramSix",
-				"/path/paramSeven",
-				"/path/paramEight",
-				"/path/paramNine",
-				"/path/paramTen",
-				"/path/paramEleven",
-				"/path/paramTwelve",
-				"/path/paramThirteen",
-				"/path/paramFourteen",
-				"/path/paramFifteen",
-				"/path/paramSixteen",
-				"/path/paramSeventeen",
-				"/path/paramEighteen",
-				"/path/paramNineteen",
-				"/path/paramTwenty",
-				"/path/paramTwentyOne",
-				"/path/paramTwentyTwo",
-			},
+	}
+	expected := map[string][]Version{
+		"alpha": {
+			{Parameter: Parameter{Value: "one", Type: types.ParameterTypeString}, Version: 1, Labels: []string{"release"}, Modified: monday, ModifiedBy: "alice"},
+			{Parameter: Parameter{Value: "two", Type: types.ParameterTypeSecureString, KeyID: "alias/aws/ssm"}, Version: 2, Modified: tuesday, ModifiedBy: "bob"},
 		},
 	}
 
-	path := util.NewParameterStorePath("/path/")
-	for i, c := range cases {
-		m := mockedDeleteDelta{
-			deleteSuccessful:          c.DeleteSuccessful,
-			getParametersByPathRetVal: c.GetParamsRetVal,
-		}
+	path := util.NewParameterStorePath("/path/parameter")
+	history, err := ReadPathHistory(context.Background(), *path, m, WriteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(expected, history) {
+		t.Fatalf("expected %v, got %v", expected, history)
+	}
 
-		deletedParams, err := DeleteDeltaFromParameterStore(
-			c.FileParams,
-			*path,
-			true,
-			&m,
-		)
+	throttled := mockedThrottledHistory{mockedGetParameter: m, throttled: make(map[string]bool)}
+	history, err = ReadPathHistory(context.Background(), *path, throttled, WriteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(expected, history) || !throttled.throttled["/path/parameter/alpha"] {
+		t.Fatalf("expected %v after retrying, got %v", expected, history)
+	}
 
-		if err != nil {
-			t.Fatalf("%d unexpected error %v", i, err)
-		}
+	if _, err := ReadPathHistory(context.Background(), *path, struct{ SSMClient }{m}, WriteOptions{}); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected %v, got %v", errors.ErrUnsupported, err)
+	}
+}
 
-		for _, param := range deletedParams {
-			_, present := findStringInSlice(c.Expected, param)
-			if !present {
-				t.Fatalf("%d expected %s in %s\n", i, c.Expected, param)
-			}
-		}
+// mockedThrottledHistory throttles the first history request for each parameter
+type mockedThrottledHistory struct {
+	*mockedGetParameter
+	throttled map[string]bool
+}
+
+func (m mockedThrottledHistory) GetParameterHistory(ctx context.Context, input *ssm.GetParameterHistoryInput, opts ...func(*ssm.Options)) (*ssm.GetParameterHistoryOutput, error) {
+	if !m.throttled[*input.Name] {
+		m.throttled[*input.Name] = true
+		return nil, &types.ThrottlingException{Message: aws.String("slow it down")}
+	}
+	return m.mockedGetParameter.GetParameterHistory(ctx, input, opts...)
+}
+
+// mockedThrottledTags throttles every other tag request, and keeps the tags it's given
+type mockedThrottledTags struct {
+	mockedDeleteDelta
+	calls int
+	tags  map[string][]types.Tag
+}
+
+func (m *mockedThrottledTags) ListTagsForResource(ctx context.Context, input *ssm.ListTagsForResourceInput, opts ...func(*ssm.Options)) (*ssm.ListTagsForResourceOutput, error) {
+	m.calls++
+	if m.calls%2 == 1 {
+		return nil, &types.ThrottlingException{Message: aws.String("slow it down")}
+	}
+	return &ssm.ListTagsForResourceOutput{TagList: m.tags[*input.ResourceId]}, nil
+}
+
+func (m *mockedThrottledTags) AddTagsToResource(ctx context.Context, input *ssm.AddTagsToResourceInput, opts ...func(*ssm.Options)) (*ssm.AddTagsToResourceOutput, error) {
+	m.calls++
+	if m.calls%2 == 1 {
+		return nil, &types.ThrottlingException{Message: aws.String("slow it down")}
+	}
+	m.tags[*input.ResourceId] = input.Tags
+	return &ssm.AddTagsToResourceOutput{}, nil
+}
+
+func TestTagsRetryThrottled(t *testing.T) {
+	team := []types.Tag{{Key: aws.String("team"), Value: aws.String("platform")}}
+	m := &mockedThrottledTags{tags: map[string][]types.Tag{"/src/alpha": team}}
+
+	tags, err := ReadTags(context.Background(), []string{"/src/alpha", "/src/beta"}, m, WriteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if expected := map[string][]types.Tag{"/src/alpha": team}; !reflect.DeepEqual(expected, tags) {
+		t.Fatalf("expected %v, got %v", expected, tags)
 	}
+
+	tagged, err := WriteTags(context.Background(), map[string][]types.Tag{"/dst/alpha": team}, m, WriteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if expected := []string{"/dst/alpha"}; !reflect.DeepEqual(expected, tagged) || m.tags["/dst/alpha"] == nil {
+		t.Fatalf("expected %v tagged, got %v", expected, tagged)
+	}
+	if m.calls != 6 {
+		t.Errorf("expected every tag request to be retried once, got %d calls", m.calls)
+	}
+
 }
@@ -0,0 +1,68 @@
+package io
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// ReadTags gets the tags of each of the named parameters, leaving out parameters without tags.
+// Requests keep to the rate and retry budget of opts, and throttled requests are retried like writes.
+func ReadTags(ctx context.Context, names []string, client SSMClient, opts WriteOptions) (map[string][]types.Tag, error) {
+	tagger, ok := client.(TagClient)
+	if !ok {
+		return nil, unsupported("read tags")
+	}
+	w := newWriter(client, opts)
+	tags := make(map[string][]types.Tag)
+	for _, name := range names {
+		var output *ssm.ListTagsForResourceOutput
+		_, err := w.call(ctx, "reading the tags of "+name, func() error {
+			var err error
+			output, err = tagger.ListTagsForResource(ctx, &ssm.ListTagsForResourceInput{
+				ResourceId:   aws.String(name),
+				ResourceType: types.ResourceTypeForTaggingParameter,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(output.TagList) > 0 {
+			tags[name] = output.TagList
+		}
+	}
+	return tags, nil
+}
+
+// WriteTags adds tags to each of the named parameters. Tags a parameter already has with the same keys are overwritten,
+// and its other tags are left alone. It returns the names of the parameters tagged before any error.
+// Requests keep to the rate and retry budget of opts, and throttled requests are retried like writes.
+func WriteTags(ctx context.Context, tags map[string][]types.Tag, client SSMClient, opts WriteOptions) ([]string, error) {
+	tagged := make([]string, 0, len(tags))
+	if len(tags) == 0 {
+		return tagged, nil
+	}
+	tagger, ok := client.(TagClient)
+	if !ok {
+		return tagged, unsupported("add tags")
+	}
+	w := newWriter(client, opts)
+	for name, t := range tags {
+		_, err := w.call(ctx, "tagging "+name, func() error {
+			_, err := tagger.AddTagsToResource(ctx, &ssm.AddTagsToResourceInput{
+				ResourceId:   aws.String(name),
+				ResourceType: types.ResourceTypeForTaggingParameter,
+				Tags:         t,
+			})
+			return err
+		})
+		if err != nil {
+			return tagged, err
+		}
+		tagged = append(tagged, name)
+	}
+	return tagged, nil
+}
@@ -3,10 +3,25 @@ package json
 import (
 	"bytes"
 	"encoding/json"
-	"log"
+
+	"github.com/pbs/gorson/internal/gorson/io"
 )
 
-func Marshal(parameters map[string]string) string {
+func Marshal(parameters map[string]string) (string, error) {
+	return marshal(parameters)
+}
+
+// MarshalNested json-formats parameters nested by util.Nest
+func MarshalNested(parameters map[string]interface{}) (string, error) {
+	return marshal(parameters)
+}
+
+// MarshalParameters json-formats parameters along with their types, descriptions and KMS keys
+func MarshalParameters(parameters map[string]io.Parameter) (string, error) {
+	return marshal(parameters)
+}
+
+func marshal(parameters interface{}) (string, error) {
 	// we use a custom encoder here because the standard library
 	// json.Marshal cannot be configured not to escape characters like
 	// & < >
@@ -16,12 +31,12 @@ func Marshal(parameters map[string]string) string {
 	enc.SetEscapeHTML(false)
 
 	if err := enc.Encode(&parameters); err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	ibuf := new(bytes.Buffer)
 	err := json.Indent(ibuf, buf.Bytes(), "", "    ")
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	return ibuf.String()
+	return ibuf.String(), nil
 }
@@ -31,7 +31,10 @@ var testpairs = []testpair{
 
 func TestParamsToJson(t *testing.T) {
 	for _, pair := range testpairs {
-		output := Marshal(pair.input)
+		output, err := Marshal(pair.input)
+		if err != nil {
+			t.Error("For", pair.input, "unexpected error", err)
+		}
 		if output != pair.expected {
 			t.Error(
 				"For", pair.input,
@@ -41,3 +44,28 @@ func TestParamsToJson(t *testing.T) {
 		}
 	}
 }
+
+func TestMarshalNested(t *testing.T) {
+	input := map[string]interface{}{
+		"db":   map[string]interface{}{"host": "localhost"},
+		"name": "app",
+	}
+	expected := `{
+    "db": {
+        "host": "localhost"
+    },
+    "name": "app"
+}
+`
+	output, err := MarshalNested(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expected {
+		t.Error(
+			"For", input,
+			"expected", expected,
+			"got", output,
+		)
+	}
+}
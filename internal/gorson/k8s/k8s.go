@@ -0,0 +1,138 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pbs/gorson/internal/gorson/util"
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// invalidKeyCharacters matches what a ConfigMap or Secret data key can't hold
+	invalidKeyCharacters = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+	// subdomain is a DNS-1123 subdomain, which ConfigMap and Secret names must be
+	subdomain = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	// label is a DNS-1123 label, which namespaces must be
+	label = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// labelName is the name part of a label key, and the form of a non-empty label value
+	labelName = regexp.MustCompile(`^[a-zA-Z0-9]([-._a-zA-Z0-9]*[a-zA-Z0-9])?$`)
+	// invalidNameCharacters matches runs of what NameFromPath replaces with a dash
+	invalidNameCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Options are the metadata of a generated manifest
+type Options struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+}
+
+type metadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type manifest struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   metadata          `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data"`
+}
+
+// Secret renders parameters as an Opaque Secret manifest, with base64 encoded data
+func Secret(parameters map[string]string, opts Options) (string, error) {
+	data, err := SanitizeKeys(parameters)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range data {
+		data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	return render(manifest{Kind: "Secret", Type: "Opaque", Data: data}, opts)
+}
+
+// ConfigMap renders parameters as a ConfigMap manifest
+func ConfigMap(parameters map[string]string, opts Options) (string, error) {
+	data, err := SanitizeKeys(parameters)
+	if err != nil {
+		return "", err
+	}
+	return render(manifest{Kind: "ConfigMap", Data: data}, opts)
+}
+
+func render(m manifest, opts Options) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", err
+	}
+	m.APIVersion = "v1"
+	m.Metadata = metadata{Name: opts.Name, Namespace: opts.Namespace, Labels: opts.Labels}
+	serialized, err := yaml.Marshal(m)
+	return string(serialized), err
+}
+
+func (opts Options) validate() error {
+	if opts.Name == "" {
+		return errors.New("a name is required")
+	}
+	if len(opts.Name) > 253 || !subdomain.MatchString(opts.Name) {
+		return fmt.Errorf("name %s invalid: it must be lowercase letters, digits, - and ., starting and ending with a letter or digit", opts.Name)
+	}
+	if opts.Namespace != "" && (len(opts.Namespace) > 63 || !label.MatchString(opts.Namespace)) {
+		return fmt.Errorf("namespace %s invalid: it must be at most 63 lowercase letters, digits and -, starting and ending with a letter or digit", opts.Namespace)
+	}
+	for k, v := range opts.Labels {
+		if !validLabelKey(k) {
+			return fmt.Errorf("label %s invalid: it must be an optional DNS subdomain prefix and /, then at most 63 letters, digits, -, _ and .", k)
+		}
+		if v != "" && (len(v) > 63 || !labelName.MatchString(v)) {
+			return fmt.Errorf("label %s value %s invalid: it must be at most 63 letters, digits, -, _ and ., starting and ending with a letter or digit", k, v)
+		}
+	}
+	return nil
+}
+
+func validLabelKey(key string) bool {
+	name := key
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		prefix := key[:i]
+		if len(prefix) > 253 || !subdomain.MatchString(prefix) {
+			return false
+		}
+		name = key[i+1:]
+	}
+	return len(name) <= 63 && labelName.MatchString(name)
+}
+
+// SanitizeKeys replaces each character a ConfigMap or Secret data key can't hold with _, like the / in db/host.
+// It returns an InvalidKeyError when two keys end up the same, rather than silently dropping one.
+func SanitizeKeys(parameters map[string]string) (map[string]string, error) {
+	keys := maps.Keys(parameters)
+	sort.Strings(keys)
+	sanitized := make(map[string]string, len(parameters))
+	sources := make(map[string]string, len(parameters))
+	for _, key := range keys {
+		s := invalidKeyCharacters.ReplaceAllString(key, "_")
+		if s == "." || s == ".." || len(s) > 253 {
+			return nil, &util.InvalidKeyError{Key: key, Reason: "it can't be used as a ConfigMap or Secret key"}
+		}
+		if other, ok := sources[s]; ok {
+			return nil, &util.InvalidKeyError{Key: key, Reason: fmt.Sprintf("it collides with %s, as both become %s in a ConfigMap or Secret", other, s)}
+		}
+		sources[s] = key
+		sanitized[s] = parameters[key]
+	}
+	return sanitized, nil
+}
+
+// NameFromPath makes a manifest name from a parameter store path, like app-prod from /app/prod/
+func NameFromPath(path string) string {
+	return strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(path), "-"), "-")
+}
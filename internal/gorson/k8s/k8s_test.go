@@ -0,0 +1,107 @@
+package k8s
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pbs/gorson/internal/gorson/util"
+)
+
+func TestSecret(t *testing.T) {
+	output, err := Secret(map[string]string{"db/host": "db.internal", "password": "it's ☃"}, Options{
+		Name:      "app-prod",
+		Namespace: "apps",
+		Labels:    map[string]string{"app.kubernetes.io/name": "app", "team": "web"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+kind: Secret
+metadata:
+  name: app-prod
+  namespace: apps
+  labels:
+    app.kubernetes.io/name: app
+    team: web
+type: Opaque
+data:
+  db_host: ZGIuaW50ZXJuYWw=
+  password: aXQncyDimIM=
+`
+	if output != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, output)
+	}
+}
+
+func TestConfigMap(t *testing.T) {
+	output, err := ConfigMap(map[string]string{"greeting": "hello\nworld", "APP_ENV": "prod"}, Options{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  APP_ENV: prod
+  greeting: |-
+    hello
+    world
+`
+	if output != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, output)
+	}
+}
+
+func TestSanitizeKeys(t *testing.T) {
+	sanitized, err := SanitizeKeys(map[string]string{"db/host": "a", "api key": "b", "ok-key.name_1": "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"db_host", "api_key", "ok-key.name_1"} {
+		if _, ok := sanitized[key]; !ok {
+			t.Errorf("expected %s in %v", key, sanitized)
+		}
+	}
+	_, err = SanitizeKeys(map[string]string{"db/host": "a", "db_host": "b"})
+	var invalidKey *util.InvalidKeyError
+	if !errors.As(err, &invalidKey) || !strings.Contains(err.Error(), "collides with db/host") {
+		t.Errorf("expected a collision error, got %v", err)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	cases := []struct {
+		opts  Options
+		valid bool
+	}{
+		{Options{Name: "app"}, true},
+		{Options{Name: "app.prod-1", Namespace: "team-a", Labels: map[string]string{"example.com/tier": "back_end", "empty": ""}}, true},
+		{Options{}, false},
+		{Options{Name: "App"}, false},
+		{Options{Name: "app", Namespace: "team.a"}, false},
+		{Options{Name: "app", Labels: map[string]string{"tier": "-bad"}}, false},
+		{Options{Name: "app", Labels: map[string]string{"Bad_Prefix/tier": "x"}}, false},
+	}
+	for i, c := range cases {
+		if err := c.opts.validate(); (err == nil) != c.valid {
+			t.Errorf("%d: expected valid to be %v, got %v", i, c.valid, err)
+		}
+	}
+}
+
+func TestNameFromPath(t *testing.T) {
+	cases := map[string]string{
+		"/app/prod/":      "app-prod",
+		"/App_Name/Prod":  "app-name-prod",
+		"/":               "",
+		"/a/parameter/p/": "a-parameter-p",
+	}
+	for path, expected := range cases {
+		if name := NameFromPath(path); name != expected {
+			t.Errorf("expected %s for %s, got %s", expected, path, name)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package merge
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+)
+
+// Layer is the parameters read from a single source, a parameter store path or a file
+type Layer struct {
+	Source     string
+	Parameters map[string]string
+}
+
+// Origin is where a single merged key came from
+type Origin struct {
+	Key string `json:"key"`
+	// Source is the last source holding the key, whose value won
+	Source string `json:"source"`
+	// Overrides are the earlier sources holding the key, in order
+	Overrides []string `json:"overrides,omitempty"`
+}
+
+// Explanation is the origin of every merged key, sorted by key
+type Explanation struct {
+	Sources []string `json:"sources"`
+	Keys    []Origin `json:"keys"`
+}
+
+// Merge merges layers left to right, so a key in a later layer replaces the same key from earlier ones
+func Merge(layers []Layer) (map[string]string, Explanation) {
+	merged := make(map[string]string)
+	sources := make(map[string][]string)
+	explanation := Explanation{Sources: make([]string, 0, len(layers))}
+	for _, layer := range layers {
+		explanation.Sources = append(explanation.Sources, layer.Source)
+		for k, v := range layer.Parameters {
+			merged[k] = v
+			sources[k] = append(sources[k], layer.Source)
+		}
+	}
+	keys := make([]string, 0, len(sources))
+	for k := range sources {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	explanation.Keys = make([]Origin, 0, len(keys))
+	for _, k := range keys {
+		s := sources[k]
+		explanation.Keys = append(explanation.Keys, Origin{Key: k, Source: s[len(s)-1], Overrides: s[:len(s)-1]})
+	}
+	return merged, explanation
+}
+
+// Text renders the explanation as aligned lines of key and source, without any values,
+// followed by a summary of how many keys one source overrode in another
+func (e Explanation) Text() string {
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	overridden := 0
+	for _, o := range e.Keys {
+		line := o.Key + "\t" + o.Source
+		if len(o.Overrides) > 0 {
+			overridden++
+			line += "\t" + yellow("(overrides "+strings.Join(o.Overrides, ", ")+")")
+		}
+		fmt.Fprintln(w, line)
+	}
+	_ = w.Flush()
+	fmt.Fprintf(buf, "%d keys from %d sources, %d overridden", len(e.Keys), len(e.Sources), overridden)
+	return buf.String()
+}
@@ -0,0 +1,48 @@
+package merge
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestMerge(t *testing.T) {
+	layers := []Layer{
+		{Source: "/shared/", Parameters: map[string]string{"db_host": "shared-db", "region": "us-east-1"}},
+		{Source: "/app/common/", Parameters: map[string]string{"db_host": "common-db", "name": "app"}},
+		{Source: "./local.env", Parameters: map[string]string{}},
+		{Source: "/app/prod/", Parameters: map[string]string{"db_host": "prod-db"}},
+	}
+	merged, explanation := Merge(layers)
+
+	expected := map[string]string{"db_host": "prod-db", "region": "us-east-1", "name": "app"}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected %v, got %v", expected, merged)
+	}
+	expectedExplanation := Explanation{
+		Sources: []string{"/shared/", "/app/common/", "./local.env", "/app/prod/"},
+		Keys: []Origin{
+			{Key: "db_host", Source: "/app/prod/", Overrides: []string{"/shared/", "/app/common/"}},
+			{Key: "name", Source: "/app/common/", Overrides: []string{}},
+			{Key: "region", Source: "/shared/", Overrides: []string{}},
+		},
+	}
+	if !reflect.DeepEqual(explanation, expectedExplanation) {
+		t.Errorf("expected %v, got %v", expectedExplanation, explanation)
+	}
+}
+
+func TestText(t *testing.T) {
+	color.NoColor = true
+	_, explanation := Merge([]Layer{
+		{Source: "/shared/", Parameters: map[string]string{"db_host": "shared-db", "region": "us-east-1"}},
+		{Source: "/app/prod/", Parameters: map[string]string{"db_host": "prod-db"}},
+	})
+	expected := "db_host  /app/prod/  (overrides /shared/)\n" +
+		"region   /shared/\n" +
+		"2 keys from 2 sources, 1 overridden"
+	if text := explanation.Text(); text != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, text)
+	}
+}
@@ -0,0 +1,212 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pbs/gorson/internal/gorson/diff"
+	"github.com/pbs/gorson/internal/gorson/io"
+	"github.com/pbs/gorson/internal/gorson/util"
+)
+
+// Action is what a put will do with a single key
+type Action string
+
+const (
+	// Create writes a key that is not yet in parameter store
+	Create Action = "create"
+	// Update overwrites a key whose value differs from parameter store
+	Update Action = "update"
+	// Unchanged leaves a key that already matches parameter store alone
+	Unchanged Action = "unchanged"
+	// Delete removes a key that is in parameter store, but not in the file
+	Delete Action = "delete"
+	// Keep leaves a key that is in parameter store, but not in the file, alone
+	Keep Action = "keep"
+	// Protected leaves a key that would be deleted alone, because it matches a protected pattern
+	Protected Action = "protected"
+)
+
+// Step is the planned action for a single key
+type Step struct {
+	Key    string `json:"key"`
+	Action Action `json:"action"`
+}
+
+// Plan is the full set of actions a put will take against a parameter store path
+type Plan struct {
+	Path  string `json:"path"`
+	Steps []Step `json:"steps"`
+	// values holds the old and new value of each key, so TextWith can show them. It's never rendered as json.
+	values map[string]diff.Change
+}
+
+// New plans writing local parameters over the remote parameters at a path.
+// Keys missing from local parameters are only deleted when deleteDelta is set.
+func New(path util.ParameterStorePath, local map[string]io.Parameter, remote map[string]io.Parameter, deleteDelta bool) Plan {
+	localValues := make(map[string]string, len(local))
+	for k, parameter := range local {
+		localValues[k] = parameter.Value
+	}
+	remoteValues := make(map[string]string, len(remote))
+	for k, parameter := range remote {
+		remoteValues[k] = parameter.Value
+	}
+	changes := diff.Compute(localValues, remoteValues)
+	steps := make([]Step, len(changes))
+	values := make(map[string]diff.Change, len(changes))
+	for i, c := range changes {
+		values[c.Key] = c
+		var action Action
+		switch c.Kind {
+		case diff.Added:
+			action = Create
+		case diff.Changed:
+			action = Update
+		case diff.Unchanged:
+			if metadataChanged(local[c.Key], remote[c.Key]) {
+				action = Update
+			} else {
+				action = Unchanged
+			}
+		case diff.Removed:
+			if deleteDelta {
+				action = Delete
+			} else {
+				action = Keep
+			}
+		}
+		steps[i] = Step{Key: c.Key, Action: action}
+	}
+	return Plan{Path: path.String(), Steps: steps, values: values}
+}
+
+// metadataChanged reports whether a local parameter with the same value as the remote one still needs to be written.
+// Types, descriptions and KMS keys are only compared when the local parameter has one, so a plain key/value file
+// doesn't rewrite String parameters whose values match, and a different KMS key re-encrypts the parameter.
+func metadataChanged(local io.Parameter, remote io.Parameter) bool {
+	if local.Type != "" && local.Type != remote.Type {
+		return true
+	}
+	if local.KeyID != "" && local.KeyID != remote.KeyID {
+		return true
+	}
+	return local.Description != "" && local.Description != remote.Description
+}
+
+// Protect turns the deletes of keys matching a protected pattern into Protected steps, so they are never deleted.
+// See util.IsProtected for how patterns match.
+func (p Plan) Protect(patterns []string) (Plan, error) {
+	path := util.NewParameterStorePath(p.Path)
+	steps := make([]Step, len(p.Steps))
+	for i, s := range p.Steps {
+		if s.Action == Delete {
+			protected, err := util.IsProtected(*path, s.Key, patterns)
+			if err != nil {
+				return Plan{}, err
+			}
+			if protected {
+				s.Action = Protected
+			}
+		}
+		steps[i] = s
+	}
+	return Plan{Path: p.Path, Steps: steps, values: p.values}, nil
+}
+
+// Narrow turns the deletes of keys that don't pass include and exclude patterns into Keep steps,
+// so syncing some of a path's keys only deletes among those keys. See util.FilterKeys for how patterns match.
+func (p Plan) Narrow(include []string, exclude []string) (Plan, error) {
+	steps := make([]Step, len(p.Steps))
+	for i, s := range p.Steps {
+		if s.Action == Delete {
+			matched, err := util.FilterKeys([]string{s.Key}, include, exclude)
+			if err != nil {
+				return Plan{}, err
+			}
+			if len(matched) == 0 {
+				s.Action = Keep
+			}
+		}
+		steps[i] = s
+	}
+	return Plan{Path: p.Path, Steps: steps, values: p.values}, nil
+}
+
+// Keys returns the keys of all steps with the given action
+func (p Plan) Keys(action Action) []string {
+	keys := make([]string, 0)
+	for _, s := range p.Steps {
+		if s.Action == action {
+			keys = append(keys, s.Key)
+		}
+	}
+	return keys
+}
+
+// Text renders the plan as colored, human-readable lines, leaving values out. Unchanged and kept keys are only counted,
+// but protected keys are listed, so it's clear why they weren't deleted.
+func (p Plan) Text() string {
+	return p.TextWith(false)
+}
+
+// TextWith renders the plan like Text, adding the value each created, updated and deleted key has
+// before and after the put when showValues is set. Without it values stay masked by leaving them out.
+func (p Plan) TextWith(showValues bool) string {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	counts := make(map[Action]int)
+	lines := make([]string, 0, len(p.Steps)+1)
+	for _, s := range p.Steps {
+		counts[s.Action]++
+		name := p.Path + s.Key
+		c := p.values[s.Key]
+		switch s.Action {
+		case Create:
+			if showValues {
+				name += " = " + c.New
+			}
+			lines = append(lines, green("+ create "+name))
+		case Update:
+			if showValues {
+				name += fmt.Sprintf(" = %s -> %s", c.Old, c.New)
+			}
+			lines = append(lines, yellow("~ update "+name))
+		case Delete:
+			if showValues {
+				name += " = " + c.Old
+			}
+			lines = append(lines, red("- delete "+name))
+		case Protected:
+			lines = append(lines, cyan("= kept (protected) "+name))
+		}
+	}
+	summary := fmt.Sprintf("Plan: %d to create, %d to update, %d to delete, %d unchanged",
+		counts[Create], counts[Update], counts[Delete], counts[Unchanged])
+	if counts[Keep] > 0 {
+		summary += fmt.Sprintf(", %d kept", counts[Keep])
+	}
+	if counts[Protected] > 0 {
+		summary += fmt.Sprintf(", %d kept (protected)", counts[Protected])
+	}
+	lines = append(lines, summary)
+	return strings.Join(lines, "\n")
+}
+
+// JSON renders the plan as an indented json document for machine consumption
+func (p Plan) JSON() (string, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(&p); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,257 @@
+package plan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/fatih/color"
+	"github.com/pbs/gorson/internal/gorson/io"
+	"github.com/pbs/gorson/internal/gorson/util"
+)
+
+type newTestCase struct {
+	local       map[string]io.Parameter
+	remote      map[string]io.Parameter
+	deleteDelta bool
+	expected    []Step
+}
+
+var newTestCases = []newTestCase{
+	{
+		local: map[string]io.Parameter{"alpha": {Value: "one"}, "beta": {Value: "two"}, "gamma": {Value: "three"}},
+		remote: map[string]io.Parameter{
+			"beta":  {Value: "two", Type: types.ParameterTypeSecureString},
+			"gamma": {Value: "four", Type: types.ParameterTypeSecureString},
+			"delta": {Value: "five", Type: types.ParameterTypeSecureString},
+		},
+		deleteDelta: false,
+		expected: []Step{
+			{Key: "alpha", Action: Create},
+			{Key: "beta", Action: Unchanged},
+			{Key: "delta", Action: Keep},
+			{Key: "gamma", Action: Update},
+		},
+	},
+	{
+		local: map[string]io.Parameter{"alpha": {Value: "one"}},
+		remote: map[string]io.Parameter{
+			"delta": {Value: "five", Type: types.ParameterTypeSecureString},
+		},
+		deleteDelta: true,
+		expected: []Step{
+			{Key: "alpha", Action: Create},
+			{Key: "delta", Action: Delete},
+		},
+	},
+	// parameters without a type match whatever type their value is stored as
+	{
+		local: map[string]io.Parameter{"alpha": {Value: "one"}, "beta": {Value: "two"}},
+		remote: map[string]io.Parameter{
+			"alpha": {Value: "one", Type: types.ParameterTypeString},
+			"beta":  {Value: "two", Type: types.ParameterTypeSecureString},
+		},
+		deleteDelta: false,
+		expected: []Step{
+			{Key: "alpha", Action: Unchanged},
+			{Key: "beta", Action: Unchanged},
+		},
+	},
+	// parameters with a type or description are rewritten when either differs
+	{
+		local: map[string]io.Parameter{
+			"alpha": {Value: "one", Type: types.ParameterTypeString},
+			"beta":  {Value: "a,b", Type: types.ParameterTypeStringList},
+			"gamma": {Value: "three", Description: "the new description"},
+			"delta": {Value: "four", Type: types.ParameterTypeString},
+		},
+		remote: map[string]io.Parameter{
+			"alpha": {Value: "one", Type: types.ParameterTypeString},
+			"beta":  {Value: "a,b", Type: types.ParameterTypeString},
+			"gamma": {Value: "three", Type: types.ParameterTypeSecureString, Description: "the old description"},
+			"delta": {Value: "four", Type: types.ParameterTypeString, Description: "kept as is"},
+		},
+		deleteDelta: false,
+		expected: []Step{
+			{Key: "alpha", Action: Unchanged},
+			{Key: "beta", Action: Update},
+			{Key: "delta", Action: Unchanged},
+			{Key: "gamma", Action: Update},
+		},
+	},
+	// secure strings with a different KMS key are re-encrypted
+	{
+		local: map[string]io.Parameter{
+			"alpha": {Value: "one", KeyID: "alias/app"},
+			"beta":  {Value: "two", KeyID: "alias/app"},
+			"gamma": {Value: "three"},
+		},
+		remote: map[string]io.Parameter{
+			"alpha": {Value: "one", Type: types.ParameterTypeSecureString, KeyID: "alias/aws/ssm"},
+			"beta":  {Value: "two", Type: types.ParameterTypeSecureString, KeyID: "alias/app"},
+			"gamma": {Value: "three", Type: types.ParameterTypeSecureString, KeyID: "alias/app"},
+		},
+		deleteDelta: false,
+		expected: []Step{
+			{Key: "alpha", Action: Update},
+			{Key: "beta", Action: Unchanged},
+			{Key: "gamma", Action: Unchanged},
+		},
+	},
+}
+
+func TestNew(t *testing.T) {
+	path := util.NewParameterStorePath("/path/")
+	for i, c := range newTestCases {
+		p := New(*path, c.local, c.remote, c.deleteDelta)
+		if p.Path != "/path/" {
+			t.Fatalf("%d expected path /path/, got %s", i, p.Path)
+		}
+		if !reflect.DeepEqual(c.expected, p.Steps) {
+			t.Fatalf("%d expected %v, got %v", i, c.expected, p.Steps)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	color.NoColor = true
+	path := util.NewParameterStorePath("/path/")
+	p := New(*path, newTestCases[0].local, newTestCases[0].remote, false)
+	expected := `+ create /path/alpha
+~ update /path/gamma
+Plan: 1 to create, 1 to update, 0 to delete, 1 unchanged, 1 kept`
+	if output := p.Text(); output != expected {
+		t.Errorf("expected %s, got %s", expected, output)
+	}
+}
+
+func TestNarrow(t *testing.T) {
+	path := util.NewParameterStorePath("/path/")
+	remote := map[string]io.Parameter{
+		"db_host":     {Value: "one", Type: types.ParameterTypeSecureString},
+		"db_password": {Value: "two", Type: types.ParameterTypeSecureString},
+		"name":        {Value: "three", Type: types.ParameterTypeSecureString},
+	}
+	p, err := New(*path, map[string]io.Parameter{}, remote, true).Narrow([]string{"db_*"}, []string{"*_password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Step{
+		{Key: "db_host", Action: Delete},
+		{Key: "db_password", Action: Keep},
+		{Key: "name", Action: Keep},
+	}
+	if !reflect.DeepEqual(expected, p.Steps) {
+		t.Errorf("expected %v, got %v", expected, p.Steps)
+	}
+	if _, err := p.Narrow([]string{"["}, nil); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestTextWith(t *testing.T) {
+	color.NoColor = true
+	path := util.NewParameterStorePath("/path/")
+	p := New(*path, newTestCases[0].local, newTestCases[0].remote, true)
+	if output := p.TextWith(false); output != p.Text() {
+		t.Errorf("expected masked values to render like Text, got %s", output)
+	}
+	expected := `+ create /path/alpha = one
+- delete /path/delta = five
+~ update /path/gamma = four -> three
+Plan: 1 to create, 1 to update, 1 to delete, 1 unchanged`
+	if output := p.TextWith(true); output != expected {
+		t.Errorf("expected %s, got %s", expected, output)
+	}
+}
+
+func TestProtect(t *testing.T) {
+	color.NoColor = true
+	path := util.NewParameterStorePath("/path/")
+	local := map[string]io.Parameter{"alpha": {Value: "one"}}
+	remote := map[string]io.Parameter{
+		"terraform/host": {Value: "two", Type: types.ParameterTypeSecureString},
+		"delta":          {Value: "three", Type: types.ParameterTypeSecureString},
+	}
+	p, err := New(*path, local, remote, true).Protect([]string{"terraform/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Step{
+		{Key: "alpha", Action: Create},
+		{Key: "delta", Action: Delete},
+		{Key: "terraform/host", Action: Protected},
+	}
+	if !reflect.DeepEqual(expected, p.Steps) {
+		t.Errorf("expected %v, got %v", expected, p.Steps)
+	}
+	expectedText := `+ create /path/alpha
+- delete /path/delta
+= kept (protected) /path/terraform/host
+Plan: 1 to create, 0 to update, 1 to delete, 0 unchanged, 1 kept (protected)`
+	if output := p.Text(); output != expectedText {
+		t.Errorf("expected %s, got %s", expectedText, output)
+	}
+
+	kept, err := New(*path, local, remote, false).Protect([]string{"terraform/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protected := kept.Keys(Protected); len(protected) != 0 {
+		t.Errorf("expected only deletes to be protected, got %v", protected)
+	}
+	if _, err := p.Protect([]string{"["}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestProtectPatterns(t *testing.T) {
+	path := util.NewParameterStorePath("/path/")
+	local := map[string]io.Parameter{"app": {Value: "value"}}
+	remote := map[string]io.Parameter{
+		"app":            {Value: "value"},
+		"old":            {Value: "value"},
+		"terraform/host": {Value: "value"},
+		"terraform/port": {Value: "value"},
+		"db_password":    {Value: "value"},
+	}
+	cases := []struct {
+		protected []string
+		expected  []string
+	}{
+		{nil, []string{"db_password", "old", "terraform/host", "terraform/port"}},
+		{[]string{"terraform/*"}, []string{"db_password", "old"}},
+		{[]string{"/path/db_*", "old"}, []string{"terraform/host", "terraform/port"}},
+		{[]string{"/other/db_*"}, []string{"db_password", "old", "terraform/host", "terraform/port"}},
+	}
+	for i, c := range cases {
+		p, err := New(*path, local, remote, true).Protect(c.protected)
+		if err != nil {
+			t.Fatalf("%d unexpected error %v", i, err)
+		}
+		if deletes := p.Keys(Delete); !reflect.DeepEqual(c.expected, deletes) {
+			t.Errorf("%d expected %v, got %v", i, c.expected, deletes)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	p := Plan{Path: "/path/", Steps: []Step{{Key: "alpha", Action: Delete}}}
+	expected := `{
+    "path": "/path/",
+    "steps": [
+        {
+            "key": "alpha",
+            "action": "delete"
+        }
+    ]
+}
+`
+	output, err := p.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expected {
+		t.Errorf("expected %s, got %s", expected, output)
+	}
+}
@@ -0,0 +1,58 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pbs/gorson/internal/gorson/plan"
+)
+
+// ApproveDeletes shows every step of a plan, with its values when showValues is set, then asks which of its deletes
+// to go ahead with. Answering yes approves every delete, and pick asks about each key in turn; anything else approves none.
+func ApproveDeletes(pl plan.Plan, showValues bool, in io.Reader, out io.Writer) ([]string, error) {
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	deletes := pl.Keys(plan.Delete)
+	if len(deletes) == 0 {
+		return []string{}, nil
+	}
+	fmt.Fprintln(out, pl.TextWith(showValues))
+	fmt.Fprintf(out, "Are you sure you'd like to %s %d parameters from %s?\nType %s to delete all of them, %s to choose one by one, or anything else to keep them all:\n",
+		red("delete"), len(deletes), pl.Path, green("yes"), green("pick"))
+	reader := bufio.NewReader(in)
+	answer, err := readAnswer(reader)
+	if err != nil {
+		return nil, err
+	}
+	switch answer {
+	case "yes":
+		return deletes, nil
+	case "pick":
+		approved := make([]string, 0, len(deletes))
+		for _, key := range deletes {
+			fmt.Fprintf(out, "%s %s%s? Type %s to delete it:\n", red("delete"), pl.Path, key, green("yes"))
+			answer, err := readAnswer(reader)
+			if err != nil {
+				return nil, err
+			}
+			if answer == "yes" {
+				approved = append(approved, key)
+			}
+		}
+		return approved, nil
+	}
+	return []string{}, nil
+}
+
+// readAnswer reads a single line of input. Input that ends without a newline still counts as an answer.
+func readAnswer(reader *bufio.Reader) (string, error) {
+	text, err := reader.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && text != "") {
+		return "", err
+	}
+	return strings.TrimSpace(text), nil
+}
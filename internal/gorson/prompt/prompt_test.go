@@ -0,0 +1,76 @@
+package prompt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/fatih/color"
+	gorsonio "github.com/pbs/gorson/internal/gorson/io"
+	"github.com/pbs/gorson/internal/gorson/plan"
+	"github.com/pbs/gorson/internal/gorson/util"
+)
+
+func testPlan() plan.Plan {
+	local := map[string]gorsonio.Parameter{
+		"alpha": {Value: "one"},
+		"beta":  {Value: "two"},
+		"same":  {Value: "three", Type: types.ParameterTypeSecureString},
+	}
+	remote := map[string]gorsonio.Parameter{
+		"beta":  {Value: "old", Type: types.ParameterTypeSecureString},
+		"delta": {Value: "four", Type: types.ParameterTypeSecureString},
+		"gamma": {Value: "five", Type: types.ParameterTypeSecureString},
+		"same":  {Value: "three", Type: types.ParameterTypeSecureString},
+	}
+	return plan.New(*util.NewParameterStorePath("/app/"), local, remote, true)
+}
+
+func TestApproveDeletes(t *testing.T) {
+	color.NoColor = true
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"yes approves every delete", "yes\n", []string{"delta", "gamma"}},
+		{"anything else approves none", "y\n", []string{}},
+		{"pick asks about each key", "pick\nno\nyes\n", []string{"gamma"}},
+		{"a last answer without a newline counts", "pick\nyes\nyes", []string{"delta", "gamma"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			approved, err := ApproveDeletes(testPlan(), false, strings.NewReader(tt.input), out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.expected, approved) {
+				t.Errorf("expected %v approved, got %v", tt.expected, approved)
+			}
+			if !strings.Contains(out.String(), "- delete /app/gamma\n") || strings.Contains(out.String(), "five") {
+				t.Errorf("expected the plan without values in the prompt, got %s", out.String())
+			}
+		})
+	}
+
+	out := new(bytes.Buffer)
+	if _, err := ApproveDeletes(testPlan(), true, strings.NewReader("no\n"), out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "- delete /app/gamma = five\n") {
+		t.Errorf("expected the plan with values in the prompt, got %s", out.String())
+	}
+
+	if _, err := ApproveDeletes(testPlan(), false, strings.NewReader("pick\nyes\n"), new(bytes.Buffer)); !errors.Is(err, io.EOF) {
+		t.Errorf("expected %v when input ends before every key is answered, got %v", io.EOF, err)
+	}
+	approved, err := ApproveDeletes(plan.Plan{Path: "/app/"}, false, strings.NewReader(""), new(bytes.Buffer))
+	if err != nil || len(approved) != 0 {
+		t.Errorf("expected no prompt without deletes, got %v and %v", approved, err)
+	}
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Funcs are the helpers templates can call, besides text/template's own
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"default":    defaultValue,
+		"required":   required,
+		"base64":     encodeBase64,
+		"jsonEscape": jsonEscape,
+		"shellQuote": shellQuote,
+	}
+}
+
+// Render executes a text/template with parameters as its data, so {{ .db_host }} is the value of db_host.
+// Referencing a key that isn't in parameters fails, naming the key. Keys that may be missing can be read
+// with {{ index . "db_host" }}, which is empty for a missing key, and keys with a / in them, like db/host, always need index.
+func Render(name string, text string, parameters map[string]string) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Funcs(Funcs()).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, parameters); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// defaultValue is value, or fallback when value is empty, as in {{ index . "port" | default "5432" }}
+func defaultValue(fallback string, value string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// required is value, failing with message when value is empty, as in {{ required "db_host must be set" .db_host }}
+func required(message string, value string) (string, error) {
+	if value == "" {
+		return "", errors.New(message)
+	}
+	return value, nil
+}
+
+func encodeBase64(value string) string {
+	return base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+// jsonEscape escapes value for use inside a double quoted json string, as in "password": "{{ jsonEscape .password }}"
+func jsonEscape(value string) (string, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+	quoted := strings.TrimSuffix(buf.String(), "\n")
+	return quoted[1 : len(quoted)-1], nil
+}
+
+// shellQuote single quotes value for a POSIX shell, as in PASSWORD={{ shellQuote .password }}
+func shellQuote(value string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", `'\''`))
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+var parameters = map[string]string{
+	"db_host":  "db.internal",
+	"password": `it's "secret"` + "\n",
+	"empty":    "",
+	"db/port":  "5432",
+}
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected string
+	}{
+		{"host={{ .db_host }}", "host=db.internal"},
+		{`port={{ index . "db/port" }}`, "port=5432"},
+		{`user={{ index . "user" | default "app" }}`, "user=app"},
+		{`{{ .empty | default "fallback" }}`, "fallback"},
+		{`{{ required "db_host must be set" .db_host }}`, "db.internal"},
+		{"{{ .db_host | base64 }}", "ZGIuaW50ZXJuYWw="},
+		{`{"password": "{{ jsonEscape .password }}"}`, `{"password": "it's \"secret\"\n"}`},
+		{"PASSWORD={{ shellQuote .password }}", "PASSWORD='it'\\''s \"secret\"\n'"},
+		{`{{ if index . "feature" }}on{{ else }}off{{ end }}`, "off"},
+		{"{{ range $k, $v := . }}{{ $k }} {{ end }}", "db/port db_host empty password "},
+	}
+	for _, c := range cases {
+		output, err := Render("test", c.text, parameters)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.text, err)
+			continue
+		}
+		if output != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.text, c.expected, output)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected string
+	}{
+		{"host={{ .db_hots }}", `map has no entry for key "db_hots"`},
+		{`{{ .db_hots | default "x" }}`, `map has no entry for key "db_hots"`},
+		{`{{ required "empty must be set" .empty }}`, "empty must be set"},
+		{"{{ .db_host", "unclosed action"},
+		{"{{ unknown .db_host }}", `function "unknown" not defined`},
+	}
+	for _, c := range cases {
+		_, err := Render("test", c.text, parameters)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", c.text, c.expected, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/pbs/gorson/internal/gorson/io"
+	"github.com/pbs/gorson/internal/gorson/util"
+)
+
+// Status is what happened to a single key during a put or sync
+type Status string
+
+const (
+	// Written means the key was written to parameter store
+	Written Status = "written"
+	// Failed means writing the key failed, see the entry's error for why
+	Failed Status = "failed"
+	// Skipped means the key already matched parameter store, so it wasn't written
+	Skipped Status = "skipped"
+	// Deleted means the key was deleted from parameter store
+	Deleted Status = "deleted"
+	// DeleteFailed means deleting the key failed, see the entry's error for why
+	DeleteFailed Status = "delete failed"
+)
+
+// Entry is the outcome of a put for a single key
+type Entry struct {
+	Key     string `json:"key"`
+	Status  Status `json:"status"`
+	Retries int    `json:"retries"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a put for every key in the file, sorted by key
+type Report struct {
+	Path    string  `json:"path"`
+	Entries []Entry `json:"results"`
+}
+
+// New builds a report from the results of writing parameters to a path, and the keys skipped as unchanged
+func New(path util.ParameterStorePath, results []io.WriteResult, skipped []string) Report {
+	entries := make([]Entry, 0, len(results)+len(skipped))
+	for _, result := range results {
+		entry := Entry{
+			Key:     strings.TrimPrefix(result.Name, path.String()),
+			Status:  Written,
+			Retries: result.Retries,
+		}
+		if result.Error != nil {
+			entry.Status = Failed
+			entry.Error = result.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	for _, key := range skipped {
+		entries = append(entries, Entry{Key: key, Status: Skipped})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return Report{Path: path.String(), Entries: entries}
+}
+
+// WithDeletes adds the results of deleting parameters from the report's path
+func (r Report) WithDeletes(results []io.DeleteResult) Report {
+	path := util.NewParameterStorePath(r.Path)
+	entries := append(make([]Entry, 0, len(r.Entries)+len(results)), r.Entries...)
+	for _, result := range results {
+		entry := Entry{
+			Key:     strings.TrimPrefix(result.Name, path.String()),
+			Status:  Deleted,
+			Retries: result.Retries,
+		}
+		if result.Error != nil {
+			entry.Status = DeleteFailed
+			entry.Error = result.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return Report{Path: r.Path, Entries: entries}
+}
+
+// Keys returns the keys of all entries with the given status
+func (r Report) Keys(status Status) []string {
+	keys := make([]string, 0)
+	for _, e := range r.Entries {
+		if e.Status == status {
+			keys = append(keys, e.Key)
+		}
+	}
+	return keys
+}
+
+// Text renders the report as a table of written, deleted and failed keys. Skipped keys are only counted.
+func (r Report) Text() string {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tSTATUS\tRETRIES\tERROR")
+	counts := make(map[Status]int)
+	for _, e := range r.Entries {
+		counts[e.Status]++
+		switch e.Status {
+		case Written, Deleted:
+			fmt.Fprintf(w, "%s\t%s\t%d\t\n", r.Path+e.Key, green(e.Status), e.Retries)
+		case Failed, DeleteFailed:
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", r.Path+e.Key, red(e.Status), e.Retries, e.Error)
+		}
+	}
+	w.Flush()
+
+	// written keys have no error, so drop the padding tabwriter leaves after them
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	summary := fmt.Sprintf("Put: %d written, %d failed, %d skipped", counts[Written], counts[Failed], counts[Skipped])
+	if counts[Deleted] > 0 || counts[DeleteFailed] > 0 {
+		summary += fmt.Sprintf(", %d deleted, %d failed to delete", counts[Deleted], counts[DeleteFailed])
+	}
+	lines = append(lines, summary)
+	return strings.Join(lines, "\n")
+}
+
+// JSON renders the report as an indented json document for machine consumption
+func (r Report) JSON() (string, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(&r); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
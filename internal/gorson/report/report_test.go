@@ -0,0 +1,86 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/pbs/gorson/internal/gorson/io"
+	"github.com/pbs/gorson/internal/gorson/util"
+)
+
+func testReport() Report {
+	path := util.NewParameterStorePath("/app/")
+	results := []io.WriteResult{
+		{Name: "/app/gamma", Error: errors.New("throttled: retry limit reached for /app/gamma"), Retries: 10},
+		{Name: "/app/alpha", Retries: 2},
+	}
+	return New(*path, results, []string{"beta"})
+}
+
+func TestNew(t *testing.T) {
+	expected := []Entry{
+		{Key: "alpha", Status: Written, Retries: 2},
+		{Key: "beta", Status: Skipped},
+		{Key: "gamma", Status: Failed, Retries: 10, Error: "throttled: retry limit reached for /app/gamma"},
+	}
+	r := testReport()
+	if !reflect.DeepEqual(expected, r.Entries) {
+		t.Errorf("expected %v, got %v", expected, r.Entries)
+	}
+	if keys := r.Keys(Failed); !reflect.DeepEqual([]string{"gamma"}, keys) {
+		t.Errorf("expected gamma failed, got %v", keys)
+	}
+}
+
+func TestText(t *testing.T) {
+	color.NoColor = true
+	lines := strings.Split(testReport().Text(), "\n")
+	expected := []string{
+		"KEY         STATUS   RETRIES  ERROR",
+		"/app/alpha  written  2",
+		"/app/gamma  failed   10       throttled: retry limit reached for /app/gamma",
+		"Put: 1 written, 1 failed, 1 skipped",
+	}
+	if !reflect.DeepEqual(expected, lines) {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	output, err := testReport().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"path": "/app/"`, `"status": "skipped"`, `"retries": 10`, `"error": "throttled: retry limit reached for /app/gamma"`} {
+		if !strings.Contains(output, s) {
+			t.Errorf("expected %s in %s", s, output)
+		}
+	}
+}
+
+func TestWithDeletes(t *testing.T) {
+	color.NoColor = true
+	r := testReport().WithDeletes([]io.DeleteResult{
+		{Name: "/app/beta"},
+		{Name: "/app/delta", Error: fmt.Errorf("%w: /app/delta", io.ErrNotFound)},
+	})
+	if expected := []string{"beta"}; !reflect.DeepEqual(expected, r.Keys(Deleted)) {
+		t.Errorf("expected %v deleted, got %v", expected, r.Keys(Deleted))
+	}
+	lines := strings.Split(r.Text(), "\n")
+	expected := []string{
+		"KEY         STATUS         RETRIES  ERROR",
+		"/app/alpha  written        2",
+		"/app/beta   deleted        0",
+		"/app/delta  delete failed  0        parameter not found: /app/delta",
+		"/app/gamma  failed         10       throttled: retry limit reached for /app/gamma",
+		"Put: 1 written, 1 failed, 1 skipped, 1 deleted, 1 failed to delete",
+	}
+	if !reflect.DeepEqual(expected, lines) {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+}
@@ -0,0 +1,222 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pbs/gorson/internal/gorson/io"
+	"github.com/pbs/gorson/internal/gorson/util"
+)
+
+var (
+	// ErrUnresolvable is returned for a reference to a key or parameter that doesn't exist
+	ErrUnresolvable = errors.New("unresolvable reference")
+	// ErrCycle is returned when values reference each other in a loop
+	ErrCycle = errors.New("reference cycle")
+)
+
+// reference matches ${KEY} and {{ssm:/a/parameter/name}}, along with $${KEY} and ${{ssm:/a/parameter/name}},
+// which escape them to stay as they are, less the leading $
+var reference = regexp.MustCompile(`\$?(?:\$\{([^{}]+)\}|\{\{\s*ssm:([^{}]+)\}\})`)
+
+// Lookup returns the value of a parameter by its full name, wrapping io.ErrNotFound if it doesn't exist
+type Lookup func(ctx context.Context, name string) (string, error)
+
+// HasReferences reports whether any value holds a reference, or an escaped one
+func HasReferences(parameters map[string]string) bool {
+	for _, value := range parameters {
+		if reference.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromParameterStore is a Lookup reading parameters from parameter store, one request per parent path
+func FromParameterStore(client io.SSMClient) Lookup {
+	parents := make(map[string]map[string]string)
+	return func(ctx context.Context, name string) (string, error) {
+		parent, key := split(name)
+		values, ok := parents[parent]
+		if !ok {
+			var err error
+			values, err = io.ReadFromParameterStore(ctx, *util.NewParameterStorePath(parent), client)
+			if err != nil {
+				return "", err
+			}
+			parents[parent] = values
+		}
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", io.ErrNotFound, name)
+		}
+		return value, nil
+	}
+}
+
+// split splits a full parameter name into its parent path and its key, like /shared/db/ and host
+func split(name string) (string, string) {
+	i := strings.LastIndex(name, "/")
+	return name[:i+1], name[i+1:]
+}
+
+// node is a value references can point at
+type node struct {
+	// key is a key of the parameters being resolved, when they were read from a file
+	key string
+	// name is the full name of a parameter store parameter
+	name string
+}
+
+func (n node) String() string {
+	if n.name != "" {
+		return n.name
+	}
+	return n.key
+}
+
+type resolver struct {
+	parameters map[string]string
+	path       string
+	lookup     Lookup
+	resolved   map[node]string
+	// stack holds the nodes being resolved, to catch cycles
+	stack []node
+}
+
+// Resolve returns parameters with every ${KEY} and {{ssm:/a/parameter/name}} in their values replaced, recursively.
+// ${KEY} is another key of parameters, or, in a value read through {{ssm:...}}, a parameter next to the one read.
+// path is the parameter store path parameters were read from, so ${KEY} can also be a parameter there.
+// It is empty for parameters read from a file. {{ssm:...}} reads parameters with lookup.
+func Resolve(ctx context.Context, parameters map[string]string, path string, lookup Lookup) (map[string]string, error) {
+	if path != "" {
+		path = util.NewParameterStorePath(path).String()
+	}
+	r := &resolver{parameters: parameters, path: path, lookup: lookup, resolved: make(map[node]string)}
+	keys := make([]string, 0, len(parameters))
+	for k := range parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	output := make(map[string]string, len(parameters))
+	for _, k := range keys {
+		value, err := r.resolve(ctx, r.local(k), parameters[k])
+		if err != nil {
+			return nil, err
+		}
+		output[k] = value
+	}
+	return output, nil
+}
+
+// local is the node for a key of the parameters being resolved
+func (r *resolver) local(key string) node {
+	if r.path == "" {
+		return node{key: key}
+	}
+	return node{name: r.path + key}
+}
+
+// isLocal reports whether a node is one of the parameters being resolved
+func (r *resolver) isLocal(n node) bool {
+	if n.name == "" {
+		return true
+	}
+	if r.path == "" || !strings.HasPrefix(n.name, r.path) {
+		return false
+	}
+	_, ok := r.parameters[strings.TrimPrefix(n.name, r.path)]
+	return ok
+}
+
+// read returns the value of a node, before its references are resolved
+func (r *resolver) read(ctx context.Context, n node) (string, error) {
+	if n.name == "" {
+		value, ok := r.parameters[n.key]
+		if !ok {
+			return "", errors.New("no such key")
+		}
+		return value, nil
+	}
+	if r.isLocal(n) {
+		return r.parameters[strings.TrimPrefix(n.name, r.path)], nil
+	}
+	return r.lookup(ctx, n.name)
+}
+
+// target is the node a reference in the value of n points at
+func (r *resolver) target(n node, match string) (node, error) {
+	groups := reference.FindStringSubmatch(match)
+	if key := groups[1]; key != "" {
+		switch {
+		case n.name == "":
+			return node{key: key}, nil
+		case r.isLocal(n):
+			return node{name: r.path + key}, nil
+		default:
+			parent, _ := split(n.name)
+			return node{name: parent + key}, nil
+		}
+	}
+	name := strings.TrimSpace(groups[2])
+	if !strings.HasPrefix(name, "/") {
+		return node{}, errors.New("not a full parameter name, like /shared/db/host")
+	}
+	return node{name: name}, nil
+}
+
+// resolve replaces the references in value, the value of n
+func (r *resolver) resolve(ctx context.Context, n node, value string) (string, error) {
+	if resolved, ok := r.resolved[n]; ok {
+		return resolved, nil
+	}
+	for i, m := range r.stack {
+		if m == n {
+			loop := make([]string, 0, len(r.stack)-i+1)
+			for _, m := range append(r.stack[i:], n) {
+				loop = append(loop, m.String())
+			}
+			return "", fmt.Errorf("%w: %s", ErrCycle, strings.Join(loop, " -> "))
+		}
+	}
+	r.stack = append(r.stack, n)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	var resolveErr error
+	resolved := reference.ReplaceAllStringFunc(value, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		if strings.HasPrefix(match, "$$") || strings.HasPrefix(match, "${{") {
+			return match[1:]
+		}
+		target, err := r.target(n, match)
+		if err != nil {
+			resolveErr = fmt.Errorf("%w %s in %s: %w", ErrUnresolvable, match, n, err)
+			return match
+		}
+		if value, ok := r.resolved[target]; ok {
+			return value
+		}
+		raw, err := r.read(ctx, target)
+		if err != nil {
+			resolveErr = fmt.Errorf("%w %s in %s: %w", ErrUnresolvable, match, n, err)
+			return match
+		}
+		value, err := r.resolve(ctx, target, raw)
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		return value
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	r.resolved[n] = resolved
+	return resolved, nil
+}
@@ -0,0 +1,179 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pbs/gorson/internal/gorson/io"
+)
+
+// store is a Lookup over a map of full parameter names, counting how often each is read
+type store struct {
+	values map[string]string
+	reads  map[string]int
+}
+
+func newStore(values map[string]string) *store {
+	return &store{values: values, reads: make(map[string]int)}
+}
+
+func (s *store) lookup(ctx context.Context, name string) (string, error) {
+	s.reads[name]++
+	value, ok := s.values[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", io.ErrNotFound, name)
+	}
+	return value, nil
+}
+
+var shared = map[string]string{
+	"/shared/db/host": "db.internal",
+	"/shared/db/port": "5432",
+	"/shared/db/url":  "postgres://{{ssm:/shared/db/host}}:${port}",
+	"/app/prod/token": "from-the-path",
+}
+
+func TestResolve(t *testing.T) {
+	cases := []struct {
+		name       string
+		parameters map[string]string
+		path       string
+		expected   map[string]string
+	}{
+		{
+			name:       "file keys",
+			parameters: map[string]string{"host": "{{ssm:/shared/db/host}}", "url": "https://${host}/", "plain": "value"},
+			expected:   map[string]string{"host": "db.internal", "url": "https://db.internal/", "plain": "value"},
+		},
+		{
+			name:       "nested references",
+			parameters: map[string]string{"db_url": "{{ssm:/shared/db/url}}?sslmode=require"},
+			expected:   map[string]string{"db_url": "postgres://db.internal:5432?sslmode=require"},
+		},
+		{
+			name:       "keys at the path",
+			parameters: map[string]string{"host": "{{ ssm:/shared/db/host }}", "url": "${host}/${token}"},
+			path:       "/app/prod",
+			expected:   map[string]string{"host": "db.internal", "url": "db.internal/from-the-path"},
+		},
+		{
+			name:       "escaped",
+			parameters: map[string]string{"script": "echo $${HOME} ${{ssm:/shared/db/host}}", "price": "$$5 ${ not a reference"},
+			expected:   map[string]string{"script": "echo ${HOME} {{ssm:/shared/db/host}}", "price": "$$5 ${ not a reference"},
+		},
+		{
+			name:       "the same reference twice",
+			parameters: map[string]string{"a": "${b}${b}", "b": "{{ssm:/shared/db/port}}"},
+			expected:   map[string]string{"a": "54325432", "b": "5432"},
+		},
+	}
+	for _, c := range cases {
+		output, err := Resolve(context.Background(), c.parameters, c.path, newStore(shared).lookup)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(output, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, output)
+		}
+	}
+}
+
+// TestLiteralValues shows what happens to a value written before references existed, like a script expecting
+// the shell to expand ${HOME}: it fails to resolve, and escaping it keeps it as it was
+func TestLiteralValues(t *testing.T) {
+	_, err := Resolve(context.Background(), map[string]string{"script": "cd ${HOME}"}, "", newStore(shared).lookup)
+	if !errors.Is(err, ErrUnresolvable) {
+		t.Errorf("expected %v for a literal ${HOME}, got %v", ErrUnresolvable, err)
+	}
+	output, err := Resolve(context.Background(), map[string]string{"script": "cd $${HOME}"}, "", newStore(shared).lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output["script"] != "cd ${HOME}" {
+		t.Errorf("expected the escaped value to become cd ${HOME}, got %s", output["script"])
+	}
+}
+
+func TestResolveReadsOnce(t *testing.T) {
+	s := newStore(shared)
+	parameters := map[string]string{"a": "{{ssm:/shared/db/url}}", "b": "{{ssm:/shared/db/url}}"}
+	if _, err := Resolve(context.Background(), parameters, "", s.lookup); err != nil {
+		t.Fatal(err)
+	}
+	if s.reads["/shared/db/url"] != 1 {
+		t.Errorf("expected /shared/db/url to be read once, got %d", s.reads["/shared/db/url"])
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	cases := []struct {
+		name       string
+		parameters map[string]string
+		path       string
+		err        error
+		message    string
+	}{
+		{
+			name:       "missing key",
+			parameters: map[string]string{"url": "https://${hots}/"},
+			err:        ErrUnresolvable,
+			message:    "unresolvable reference ${hots} in url: no such key",
+		},
+		{
+			name:       "missing parameter",
+			parameters: map[string]string{"host": "{{ssm:/shared/db/hots}}"},
+			err:        io.ErrNotFound,
+			message:    "unresolvable reference {{ssm:/shared/db/hots}} in host",
+		},
+		{
+			name:       "missing key at the path",
+			parameters: map[string]string{"url": "${hots}"},
+			path:       "/app/prod/",
+			err:        io.ErrNotFound,
+			message:    "unresolvable reference ${hots} in /app/prod/url",
+		},
+		{
+			name:       "relative parameter name",
+			parameters: map[string]string{"host": "{{ssm:shared/db/host}}"},
+			err:        ErrUnresolvable,
+			message:    "not a full parameter name",
+		},
+		{
+			name:       "cycle",
+			parameters: map[string]string{"a": "${b}", "b": "x${c}", "c": "${a}"},
+			err:        ErrCycle,
+			message:    "reference cycle: a -> b -> c -> a",
+		},
+		{
+			name:       "self reference",
+			parameters: map[string]string{"a": "${a}"},
+			path:       "/app/prod/",
+			err:        ErrCycle,
+			message:    "reference cycle: /app/prod/a -> /app/prod/a",
+		},
+	}
+	for _, c := range cases {
+		_, err := Resolve(context.Background(), c.parameters, c.path, newStore(shared).lookup)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.err, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.message) {
+			t.Errorf("%s: expected an error containing %q, got %q", c.name, c.message, err.Error())
+		}
+	}
+}
+
+func TestHasReferences(t *testing.T) {
+	if HasReferences(map[string]string{"a": "plain", "b": "$5 {{ not one }}"}) {
+		t.Error("expected no references")
+	}
+	if !HasReferences(map[string]string{"a": "plain", "b": "${a}"}) {
+		t.Error("expected a reference")
+	}
+}
@@ -0,0 +1,90 @@
+// Package shell writes scripts setting parameters as environment variables in shells other than bash.
+// Keys are written as they are, so callers check they're environment variable names first, as format does.
+package shell
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+// Fish generates set -gx lines. Fish single quotes only give \ and ' a meaning, so both are escaped with a backslash.
+func Fish(parameters map[string]string) (string, error) {
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return lines(parameters, func(key string, value string) string {
+		return fmt.Sprintf("set -gx %s '%s'", key, quote.Replace(value))
+	})
+}
+
+// PowerShell generates $env: assignments. PowerShell single quotes end at ' and at the typographic
+// single quotes ‘ ’ ‚ ‛, and each of them is escaped by doubling it.
+func PowerShell(parameters map[string]string) (string, error) {
+	quote := strings.NewReplacer(`'`, `''`, "‘", "‘‘", "’", "’’", "‚", "‚‚", "‛", "‛‛")
+	return lines(parameters, func(key string, value string) string {
+		return fmt.Sprintf("$env:%s = '%s'", key, quote.Replace(value))
+	})
+}
+
+// Nushell generates a load-env record of double quoted values, escaping backslashes, double quotes
+// and control characters, which nushell writes as \u{hex}
+func Nushell(parameters map[string]string) (string, error) {
+	keys := sortedKeys(parameters)
+	if len(keys) == 0 {
+		return "load-env {}", nil
+	}
+	fields := make([]string, len(keys))
+	for i, key := range keys {
+		fields[i] = fmt.Sprintf("    %s: \"%s\"", key, nushellEscape(parameters[key]))
+	}
+	return "load-env {\n" + strings.Join(fields, "\n") + "\n}", nil
+}
+
+func nushellEscape(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '\\' || r == '"':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u{%x}`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Csh generates setenv lines for csh and tcsh. A single quote closes the quotes, is escaped and reopens them,
+// ! is escaped against history substitution, and newlines are escaped, as csh won't otherwise continue a quote past one.
+func Csh(parameters map[string]string) (string, error) {
+	quote := strings.NewReplacer(`'`, `'\''`, `!`, `\!`, "\n", "\\\n")
+	return lines(parameters, func(key string, value string) string {
+		return fmt.Sprintf("setenv %s '%s'", key, quote.Replace(value))
+	})
+}
+
+// lines formats each parameter, sorted by key, as a line
+func lines(parameters map[string]string, format func(key string, value string) string) (string, error) {
+	keys := sortedKeys(parameters)
+	out := make([]string, len(keys))
+	for i, key := range keys {
+		out[i] = format(key, parameters[key])
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// sortedKeys returns the keys of parameters in order
+func sortedKeys(parameters map[string]string) []string {
+	keys := maps.Keys(parameters)
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,80 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/pbs/gorson/internal/gorson/bash"
+)
+
+// tricky holds values each shell quotes differently
+var tricky = map[string]string{
+	"plain":   "value",
+	"quotes":  `it's "quoted"`,
+	"newline": "line one\nline two",
+	"unicode": "naïve ☃ ‘smart’",
+	"special": `$HOME \n !1 ; & | *`,
+}
+
+func TestScript(t *testing.T) {
+	cases := []struct {
+		shell    string
+		script   func(map[string]string) (string, error)
+		expected string
+	}{
+		{"fish", Fish, `set -gx newline 'line one
+line two'
+set -gx plain 'value'
+set -gx quotes 'it\'s "quoted"'
+set -gx special '$HOME \\n !1 ; & | *'
+set -gx unicode 'naïve ☃ ‘smart’'`},
+		{"powershell", PowerShell, `$env:newline = 'line one
+line two'
+$env:plain = 'value'
+$env:quotes = 'it''s "quoted"'
+$env:special = '$HOME \n !1 ; & | *'
+$env:unicode = 'naïve ☃ ‘‘smart’’'`},
+		{"nushell", Nushell, `load-env {
+    newline: "line one\nline two"
+    plain: "value"
+    quotes: "it's \"quoted\""
+    special: "$HOME \\n !1 ; & | *"
+    unicode: "naïve ☃ ‘smart’"
+}`},
+		{"csh", Csh, `setenv newline 'line one\
+line two'
+setenv plain 'value'
+setenv quotes 'it'\''s "quoted"'
+setenv special '$HOME \n \!1 ; & | *'
+setenv unicode 'naïve ☃ ‘smart’'`},
+		{"bash", bash.ParamsToShell, `export newline='line one
+line two'
+export plain='value'
+export quotes='it'\''s "quoted"'
+export special='$HOME \n !1 ; & | *'
+export unicode='naïve ☃ ‘smart’'`},
+	}
+	for _, c := range cases {
+		t.Run(c.shell, func(t *testing.T) {
+			output, err := c.script(tricky)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != c.expected {
+				t.Errorf("expected\n%s\ngot\n%s", c.expected, output)
+			}
+		})
+	}
+}
+
+func TestNushellControlCharacters(t *testing.T) {
+	output, err := Nushell(map[string]string{"ctrl": "tab\there\r\x1b[0m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "load-env {\n    ctrl: \"tab\\there\\r\\u{1b}[0m\"\n}"; output != expected {
+		t.Errorf("expected %s, got %s", expected, output)
+	}
+	if output, _ := Nushell(map[string]string{}); output != "load-env {}" {
+		t.Errorf("expected an empty record, got %s", output)
+	}
+}
@@ -0,0 +1,197 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pbs/gorson/internal/gorson/io"
+)
+
+// header starts every snapshot file, so it can be recognized and its format changed later
+const header = "gorson-snapshot-v1\n"
+
+// timeLayout is how a snapshot file name holds when it was taken, so names sort oldest first
+const timeLayout = "20060102T150405.000000000Z"
+
+// keySize is the size in bytes of the AES-256 key snapshots are encrypted with
+const keySize = 32
+
+// Snapshot is the state of every parameter at a path at a point in time
+type Snapshot struct {
+	Path       string                  `json:"path"`
+	Taken      time.Time               `json:"taken"`
+	Parameters map[string]io.Parameter `json:"parameters"`
+}
+
+// Key reads the key snapshots are encrypted with from a file, creating the file with a new random key if it doesn't exist
+func Key(filename string) ([]byte, error) {
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return newKey(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("%w: %s should hold a base64 encoded %d byte key", io.ErrInvalidFile, filename, keySize)
+	}
+	return key, nil
+}
+
+func newKey(filename string) ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return nil, err
+	}
+	// O_EXCL keeps two gorsons starting at once from each writing a different key
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return Key(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(base64.StdEncoding.EncodeToString(key) + "\n"); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Save encrypts a snapshot into a new file in a directory, named after its path and when it was taken, and returns the file's name
+func (s Snapshot) Save(dir string, key []byte) (string, error) {
+	plaintext, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := encrypt(plaintext, key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	filename := filepath.Join(dir, fmt.Sprintf("%s-%s.snapshot", fileName(s.Path), s.Taken.UTC().Format(timeLayout)))
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(ciphertext); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// fileName turns a path into the start of its snapshot file names, like app_prod for /app/prod/
+func fileName(path string) string {
+	name := strings.ReplaceAll(strings.Trim(path, "/"), "/", "_")
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
+// Prune removes all but the newest keep snapshots of a path from a directory, and returns the names of the files it removed.
+// Files of other paths are left alone, even ones whose names start the same way, like those of /app-x/ next to /app/.
+func Prune(dir string, path string, keep int) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := fileName(path) + "-"
+	snapshots := make([]string, 0)
+	for _, e := range entries {
+		taken, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(timeLayout+".snapshot", taken); err == nil {
+			snapshots = append(snapshots, e.Name())
+		}
+	}
+	// os.ReadDir sorts by name, and so oldest first
+	removed := make([]string, 0)
+	for len(snapshots) > keep {
+		filename := filepath.Join(dir, snapshots[0])
+		if err := os.Remove(filename); err != nil {
+			return removed, err
+		}
+		removed = append(removed, filename)
+		snapshots = snapshots[1:]
+	}
+	return removed, nil
+}
+
+// Load decrypts a snapshot file
+func Load(filename string, key []byte) (Snapshot, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%w: %w", io.ErrInvalidFile, err)
+	}
+	plaintext, err := decrypt(content, key)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%w: error reading %s: %w", io.ErrInvalidFile, filename, err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(plaintext, &s); err != nil {
+		return Snapshot{}, fmt.Errorf("%w: error reading %s: %w", io.ErrInvalidFile, filename, err)
+	}
+	return s, nil
+}
+
+// encrypt seals plaintext with AES-256-GCM under a random nonce, authenticating the header along with it
+func encrypt(plaintext []byte, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, []byte(header))
+	return append([]byte(header), sealed...), nil
+}
+
+func decrypt(content []byte, key []byte) ([]byte, error) {
+	if !bytes.HasPrefix(content, []byte(header)) {
+		return nil, errors.New("it's not a gorson snapshot")
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed := content[len(header):]
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("it's truncated")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(header))
+	if err != nil {
+		return nil, errors.New("it can't be decrypted with this key, or it's been changed")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
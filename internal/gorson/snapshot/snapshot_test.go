@@ -0,0 +1,114 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/pbs/gorson/internal/gorson/io"
+)
+
+func TestKey(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "gorson", "snapshot.key")
+	key, err := Key(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != keySize {
+		t.Fatalf("expected a %d byte key, got %d", keySize, len(key))
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the key file to be private, got %v", info.Mode().Perm())
+	}
+	again, err := Key(filename)
+	if err != nil || !bytes.Equal(key, again) {
+		t.Errorf("expected the same key read back, got %v", err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	key, err := Key(filepath.Join(dir, "snapshot.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Snapshot{
+		Path:  "/app/prod/",
+		Taken: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+		Parameters: map[string]io.Parameter{
+			"secret": {Value: "hunter2", Type: types.ParameterTypeSecureString, KeyID: "alias/app"},
+			"name":   {Value: "app", Type: types.ParameterTypeString, Description: "the app name"},
+		},
+	}
+	filename, err := s.Save(filepath.Join(dir, "snapshots"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(filename) != "app_prod-20261017T120000.000000000Z.snapshot" {
+		t.Errorf("unexpected snapshot name %s", filename)
+	}
+	content, _ := os.ReadFile(filename)
+	if bytes.Contains(content, []byte("hunter2")) {
+		t.Error("expected values to be encrypted")
+	}
+
+	loaded, err := Load(filename, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s, loaded) {
+		t.Errorf("expected %v, got %v", s, loaded)
+	}
+
+	otherKey, _ := Key(filepath.Join(dir, "other.key"))
+	if _, err := Load(filename, otherKey); !errors.Is(err, io.ErrInvalidFile) {
+		t.Errorf("expected %v with the wrong key, got %v", io.ErrInvalidFile, err)
+	}
+	if _, err := Load("../../../fixtures/useful-parameters.json", key); !errors.Is(err, io.ErrInvalidFile) {
+		t.Errorf("expected %v for a file that isn't a snapshot, got %v", io.ErrInvalidFile, err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	key, err := Key(filepath.Join(dir, "snapshot.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	taken := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if _, err := (Snapshot{Path: "/app/", Taken: taken.Add(time.Duration(i) * time.Hour)}).Save(dir, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other, err := Snapshot{Path: "/app-x/", Taken: taken}.Save(dir, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := Prune(dir, "/app/", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{filepath.Join(dir, "app-20261017T120000.000000000Z.snapshot")}; !reflect.DeepEqual(expected, removed) {
+		t.Errorf("expected the oldest snapshot removed, got %v", removed)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("expected the snapshot of /app-x/ kept, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "snapshot.key")); err != nil {
+		t.Errorf("expected the key kept, got %v", err)
+	}
+	if removed, _ := Prune(dir, "/app/", 2); len(removed) != 0 {
+		t.Errorf("expected nothing more to remove, got %v", removed)
+	}
+}
@@ -3,6 +3,8 @@ package util
 import (
 	"fmt"
 	"golang.org/x/exp/maps"
+	"os"
+	"path"
 	"regexp"
 	"sort"
 	"strings"
@@ -28,20 +30,50 @@ func NewParameterStorePath(input string) *ParameterStorePath {
 	return &ParameterStorePath{filtered}
 }
 
+// envKey matches a valid environment variable name
+var envKey = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// IsEnvKey reports whether a key is a valid environment variable name: letters, digits and underscores, not starting with a digit
+func IsEnvKey(key string) bool {
+	return envKey.MatchString(key)
+}
+
+// lineKey matches a key ParametersToSlice can write: starting with a letter or underscore,
+// without whitespace or an = that would end it early when read back
+var lineKey = regexp.MustCompile(`^[a-zA-Z_][^\s=]*$`)
+
+// IsLineKey reports whether ParametersToSlice can write a key, and env files can hold it
+func IsLineKey(key string) bool {
+	return lineKey.MatchString(key)
+}
+
+// InvalidKeyError is returned for keys that can't be used as environment variable names,
+// or can't otherwise be written in an output format
+type InvalidKeyError struct {
+	Key string
+	// Reason says why the key can't be used, when it isn't just an invalid environment variable name
+	Reason string
+}
+
+func (e *InvalidKeyError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("Key %s invalid: %s", e.Key, e.Reason)
+	}
+	return fmt.Sprintf("Key %s invalid", e.Key)
+}
+
 // ParametersToSlice accepts a map of string key/value pairs and returns an array of strings.
 // The elements of the returned array are keys and values conjoined by an `=` sign.
-// Keys that do not start with a letter or underscore will result in an error.
+// Keys that do not start with a letter or underscore will result in an error. See IsLineKey.
 // All values are enclosed in single quotes.
 // Values that contain single quote characters will first be converted to double quotes.
 func ParametersToSlice(parameters map[string]string) ([]string, error) {
 	lines := make([]string, 0)
 	keys := maps.Keys(parameters)
 	sort.Strings(keys)
-	//envvar names must begin with a letter or underscore
-	invalidKey := regexp.MustCompile(`^[^a-zA-Z_]`)
 	for _, key := range keys {
-		if invalidKey.MatchString(key) {
-			return nil, fmt.Errorf("Key %s invalid", key)
+		if !IsLineKey(key) {
+			return nil, &InvalidKeyError{Key: key}
 		}
 		v := parameters[key]
 		if strings.Contains(v, "'") {
@@ -52,3 +84,128 @@ func ParametersToSlice(parameters map[string]string) ([]string, error) {
 	}
 	return lines, nil
 }
+
+// nonEnvCharacters matches characters that can't appear in an environment variable name
+var nonEnvCharacters = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// EnvKeys converts relative parameter names like db/host into environment variable style keys like DB_HOST.
+// Names that convert to the same key result in an error.
+func EnvKeys(parameters map[string]string) (map[string]string, error) {
+	names := maps.Keys(parameters)
+	sort.Strings(names)
+	output := make(map[string]string, len(parameters))
+	sources := make(map[string]string, len(parameters))
+	for _, name := range names {
+		key := strings.ToUpper(nonEnvCharacters.ReplaceAllString(name, "_"))
+		if other, ok := sources[key]; ok {
+			return nil, fmt.Errorf("Keys %s and %s both convert to %s", other, name, key)
+		}
+		sources[key] = name
+		output[key] = parameters[name]
+	}
+	return output, nil
+}
+
+// Nest converts relative parameter names like db/host into nested maps like {"db": {"host": ...}}.
+// A name that is both a value and a parent of other names results in an error.
+func Nest(parameters map[string]string) (map[string]interface{}, error) {
+	names := maps.Keys(parameters)
+	sort.Strings(names)
+	output := make(map[string]interface{})
+	for _, name := range names {
+		components := strings.Split(name, "/")
+		current := output
+		for i, component := range components[:len(components)-1] {
+			child, ok := current[component]
+			if !ok {
+				child = make(map[string]interface{})
+				current[component] = child
+			}
+			nested, ok := child.(map[string]interface{})
+			if !ok {
+				parent := strings.Join(components[:i+1], "/")
+				return nil, fmt.Errorf("Key %s collides with %s", parent, name)
+			}
+			current = nested
+		}
+		leaf := components[len(components)-1]
+		if _, ok := current[leaf]; ok {
+			return nil, fmt.Errorf("Key %s collides with its nested keys", name)
+		}
+		current[leaf] = parameters[name]
+	}
+	return output, nil
+}
+
+// MatchKey reports whether a key matches any of the glob patterns, like db_* or db/*.
+// Patterns follow path.Match, so * doesn't match the / in nested keys.
+func MatchKey(key string, patterns []string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, key)
+		if err != nil {
+			return false, fmt.Errorf("Pattern %s invalid: %w", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ValidatePatterns returns an error for the first malformed glob pattern
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("Pattern %s invalid: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// IsProtected reports whether a key at a path matches a protected pattern. Patterns starting with /
+// match the full parameter name, like /app/*/db_password, and others match the key below the path, like terraform/*.
+func IsProtected(p ParameterStorePath, key string, patterns []string) (bool, error) {
+	for _, pattern := range patterns {
+		name := key
+		if strings.HasPrefix(pattern, "/") {
+			name = p.String() + key
+		}
+		matched, err := MatchKey(name, []string{pattern})
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+// FilterKeys returns the keys that match an include pattern, or any key if there are none, and no exclude pattern
+func FilterKeys(keys []string, include []string, exclude []string) ([]string, error) {
+	filtered := make([]string, 0, len(keys))
+	for _, key := range keys {
+		included := true
+		if len(include) > 0 {
+			matched, err := MatchKey(key, include)
+			if err != nil {
+				return nil, err
+			}
+			included = matched
+		}
+		excluded, err := MatchKey(key, exclude)
+		if err != nil {
+			return nil, err
+		}
+		if included && !excluded {
+			filtered = append(filtered, key)
+		}
+	}
+	return filtered, nil
+}
+
+// IsTerminal reports whether a file, like os.Stdin, is an interactive terminal rather than a pipe or a regular file
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"golang.org/x/exp/slices"
+	"reflect"
 	"testing"
 )
 
@@ -25,6 +26,10 @@ var testcases = []struct {
 		map[string]string{"complex": "a$b'c-d_e;f&g\"h|i@j"},
 		[]string{`complex='a$b'\''c-d_e;f&g"h|i@j'`},
 	},
+	{
+		map[string]string{"db-host": "a", "db.port": "b", "db/name": "c"},
+		[]string{"db-host='a'", "db.port='b'", "db/name='c'"},
+	},
 }
 
 type testpair struct {
@@ -83,3 +88,78 @@ func TestParametersToSlice(t *testing.T) {
 		}
 	}
 }
+
+func TestEnvKeys(t *testing.T) {
+	output, err := EnvKeys(map[string]string{"db/host": "a", "cache/host-name": "b", "port": "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"DB_HOST": "a", "CACHE_HOST_NAME": "b", "PORT": "c"}
+	if !reflect.DeepEqual(expected, output) {
+		t.Errorf("expected %v, got %v", expected, output)
+	}
+
+	_, err = EnvKeys(map[string]string{"db/host": "a", "db_host": "b"})
+	if err == nil {
+		t.Error("expected an error for keys that collide")
+	}
+}
+
+func TestNest(t *testing.T) {
+	output, err := Nest(map[string]string{"db/host": "a", "db/port": "b", "name": "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"db":   map[string]interface{}{"host": "a", "port": "b"},
+		"name": "c",
+	}
+	if !reflect.DeepEqual(expected, output) {
+		t.Errorf("expected %v, got %v", expected, output)
+	}
+
+	for _, collision := range []map[string]string{
+		{"db": "a", "db/host": "b"},
+		{"db/host": "a", "db/host/port": "b"},
+	} {
+		if _, err := Nest(collision); err == nil {
+			t.Errorf("expected an error for %v", collision)
+		}
+	}
+}
+
+func TestFilterKeys(t *testing.T) {
+	keys := []string{"db_host", "db_password", "name", "db/host"}
+	cases := []struct {
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{nil, nil, keys},
+		{[]string{"db_*"}, nil, []string{"db_host", "db_password"}},
+		{[]string{"db_*", "db/*"}, []string{"*password"}, []string{"db_host", "db/host"}},
+		{nil, []string{"db*"}, []string{"name", "db/host"}},
+	}
+	for i, c := range cases {
+		output, err := FilterKeys(keys, c.include, c.exclude)
+		if err != nil {
+			t.Fatalf("%d unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(c.expected, output) {
+			t.Errorf("%d expected %v, got %v", i, c.expected, output)
+		}
+	}
+
+	if _, err := FilterKeys(keys, []string{"[db"}, nil); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestValidatePatterns(t *testing.T) {
+	if err := ValidatePatterns([]string{"terraform/*", "/app/*/db_password"}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := ValidatePatterns([]string{"terraform/*", "db[_"}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
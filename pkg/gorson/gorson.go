@@ -0,0 +1,790 @@
+// Package gorson reads, writes and syncs AWS parameter store paths.
+// It is the library behind the gorson command line tool.
+package gorson
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/pbs/gorson/internal/gorson/diff"
+	"github.com/pbs/gorson/internal/gorson/format"
+	"github.com/pbs/gorson/internal/gorson/history"
+	"github.com/pbs/gorson/internal/gorson/io"
+	"github.com/pbs/gorson/internal/gorson/json"
+	"github.com/pbs/gorson/internal/gorson/k8s"
+	"github.com/pbs/gorson/internal/gorson/merge"
+	"github.com/pbs/gorson/internal/gorson/plan"
+	"github.com/pbs/gorson/internal/gorson/render"
+	"github.com/pbs/gorson/internal/gorson/report"
+	"github.com/pbs/gorson/internal/gorson/resolve"
+	"github.com/pbs/gorson/internal/gorson/snapshot"
+	"github.com/pbs/gorson/internal/gorson/util"
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v2"
+)
+
+// SSMClient is the subset of the AWS SSM client gorson uses to read, write and delete parameters. *ssm.Client satisfies it,
+// along with the optional interfaces below. Methods that need one of them return errors.ErrUnsupported without it.
+type SSMClient = io.SSMClient
+
+// DescribeClient is implemented by SSM clients that can describe parameters, which reading descriptions and KMS keys needs:
+// GetParameters, Copy, Snapshot, and PutParameters with descriptions or KMS keys
+type DescribeClient = io.DescribeClient
+
+// TagClient is implemented by SSM clients that can read and add tags, which Copy needs
+type TagClient = io.TagClient
+
+// HistoryClient is implemented by SSM clients that can read the versions of a parameter, which History and Rollback need
+type HistoryClient = io.HistoryClient
+
+// Parameter is a value along with its type, description and KMS key.
+// An empty Type is written as a SecureString, and an empty KeyID as DefaultKeyID.
+type Parameter = io.Parameter
+
+// DefaultKeyID is the AWS managed KMS key SecureStrings are encrypted with when no other key is given
+const DefaultKeyID = io.DefaultKeyID
+
+// Version is a single version of a parameter from its history
+type Version = io.Version
+
+// Snapshot is the state of every parameter at a path at a point in time, saved before gorson changes it
+type Snapshot = snapshot.Snapshot
+
+// Change is the difference for a single key between local parameters and parameter store
+type Change = diff.Change
+
+// Kind describes how a single key differs between local parameters and parameter store:
+// one of "added", "changed", "removed" or "unchanged"
+type Kind = diff.Kind
+
+// Plan is the full set of actions a put or sync will take against a parameter store path
+type Plan = plan.Plan
+
+// Action is what a put or sync will do with a single key
+type Action = plan.Action
+
+// actions in a Plan
+const (
+	Create    = plan.Create
+	Update    = plan.Update
+	Unchanged = plan.Unchanged
+	Delete    = plan.Delete
+	Keep      = plan.Keep
+	Protected = plan.Protected
+)
+
+// Report is the outcome of a put for every key, written, failed or skipped
+type Report = report.Report
+
+// ReportEntry is the outcome of a put for a single key
+type ReportEntry = report.Entry
+
+// Status is what happened to a single key during a put
+type Status = report.Status
+
+// statuses in a Report
+const (
+	Written      = report.Written
+	Failed       = report.Failed
+	Skipped      = report.Skipped
+	Deleted      = report.Deleted
+	DeleteFailed = report.DeleteFailed
+)
+
+// Layer is the parameters read from a single source, a parameter store path or a file, for Merge
+type Layer = merge.Layer
+
+// Explanation is where every key from Merge came from
+type Explanation = merge.Explanation
+
+// Origin is where a single key from Merge came from, and which earlier sources it overrode
+type Origin = merge.Origin
+
+// InvalidKeyError is returned for keys that can't be used as environment variable names
+type InvalidKeyError = util.InvalidKeyError
+
+// errors returned by Client methods, for use with errors.Is
+var (
+	ErrNotFound     = io.ErrNotFound
+	ErrAccessDenied = io.ErrAccessDenied
+	ErrThrottled    = io.ErrThrottled
+	ErrTimeout      = io.ErrTimeout
+	ErrInvalidFile  = io.ErrInvalidFile
+	ErrNotTerminal  = io.ErrNotTerminal
+	// ErrUnresolvable is returned for a reference in a value to a key or parameter that doesn't exist
+	ErrUnresolvable = resolve.ErrUnresolvable
+	// ErrReferenceCycle is returned when values reference each other in a loop
+	ErrReferenceCycle = resolve.ErrCycle
+)
+
+// defaultTimeout bounds writes when no timeout is given
+const defaultTimeout = time.Minute
+
+// Client reads and writes parameter store paths
+type Client struct {
+	ssm       SSMClient
+	protected []string
+}
+
+// New returns a Client that talks to parameter store through the given SSM client.
+// A nil client uses the default AWS configuration from the environment.
+func New(ctx context.Context, client SSMClient) (*Client, error) {
+	if client == nil {
+		c, err := io.NewSSMClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		client = c
+	}
+	return &Client{ssm: client}, nil
+}
+
+// NewForProfile returns a Client using a named profile from the shared AWS config, in the given region.
+// An empty profile or region falls back to the default configuration from the environment.
+func NewForProfile(ctx context.Context, profile string, region string) (*Client, error) {
+	var optFns []func(*config.LoadOptions) error
+	if profile != "" {
+		optFns = append(optFns, config.WithSharedConfigProfile(profile))
+	}
+	if region != "" {
+		optFns = append(optFns, config.WithRegion(region))
+	}
+	client, err := io.NewSSMClient(ctx, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ssm: client}, nil
+}
+
+// Protect keeps keys matching any of the glob patterns from ever being deleted by this client, like keys managed by other tools.
+// Patterns starting with / match full parameter names, like /app/*/db_password, and others match keys below the path
+// being written, like terraform/*. Plans show those keys as Protected instead of Delete.
+func (c *Client) Protect(patterns ...string) error {
+	if err := util.ValidatePatterns(patterns); err != nil {
+		return err
+	}
+	c.protected = append(c.protected, patterns...)
+	return nil
+}
+
+// GetOptions configures Get
+type GetOptions struct {
+	// Recursive includes parameters nested below the path, keyed by their name relative to it, like db/host
+	Recursive bool
+	// Resolve replaces references like ${KEY} and {{ssm:/shared/db/host}} in values. See Resolve.
+	Resolve bool
+}
+
+// Get reads all parameters at a path
+func (c *Client) Get(ctx context.Context, path string, opts GetOptions) (map[string]string, error) {
+	p := util.NewParameterStorePath(path)
+	var parameters map[string]string
+	var err error
+	if opts.Recursive {
+		parameters, err = io.ReadFromParameterStoreRecursive(ctx, *p, c.ssm)
+	} else {
+		parameters, err = io.ReadFromParameterStore(ctx, *p, c.ssm)
+	}
+	if err != nil || !opts.Resolve {
+		return parameters, err
+	}
+	return c.Resolve(ctx, parameters, p.String())
+}
+
+// Resolve returns parameters with references in their values replaced by what they point at, recursively:
+// ${KEY} by the value of another key, and {{ssm:/shared/db/host}} by the value of that parameter.
+// path is the parameter store path parameters were read from, where ${KEY} can also be a parameter,
+// or empty for parameters read from a file. $${KEY} and ${{ssm:/shared/db/host}} escape a reference, keeping it as it is.
+// A reference that can't be resolved returns ErrUnresolvable, and references in a loop ErrReferenceCycle.
+func (c *Client) Resolve(ctx context.Context, parameters map[string]string, path string) (map[string]string, error) {
+	return resolve.Resolve(ctx, parameters, path, resolve.FromParameterStore(c.ssm))
+}
+
+// HasReferences reports whether any value holds a reference for Resolve to replace
+func HasReferences(parameters map[string]string) bool {
+	return resolve.HasReferences(parameters)
+}
+
+// GetParameters reads all parameters at a path along with their types, descriptions and KMS keys.
+// It takes more requests than Get, so use Get when only values are needed.
+func (c *Client) GetParameters(ctx context.Context, path string, opts GetOptions) (map[string]Parameter, error) {
+	p := util.NewParameterStorePath(path)
+	return io.ReadParametersWithMetadata(ctx, *p, opts.Recursive, c.ssm)
+}
+
+// Diff compares parameters against those at a path, returning one change per key sorted by key
+func (c *Client) Diff(ctx context.Context, path string, parameters map[string]string) ([]Change, error) {
+	remote, err := c.Get(ctx, path, GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return diff.Compute(parameters, remote), nil
+}
+
+// HasDrift reports whether any change from Diff is not unchanged
+func HasDrift(changes []Change) bool {
+	return diff.HasDrift(changes)
+}
+
+// Plan works out what writing parameters to a path would do, without writing anything.
+// Keys at the path that are missing from parameters are only planned for deletion when deleteDelta is set.
+func (c *Client) Plan(ctx context.Context, path string, parameters map[string]string, deleteDelta bool) (Plan, error) {
+	return c.PlanParameters(ctx, path, secureStrings(parameters), deleteDelta)
+}
+
+// PlanParameters is Plan for parameters with types, descriptions and KMS keys
+func (c *Client) PlanParameters(ctx context.Context, path string, parameters map[string]Parameter, deleteDelta bool) (Plan, error) {
+	p := util.NewParameterStorePath(path)
+	var remote map[string]Parameter
+	var err error
+	// descriptions and KMS keys take extra requests to read, so we only read them when there are some to compare
+	if hasMetadata(parameters) {
+		remote, err = io.ReadParametersWithMetadata(ctx, *p, false, c.ssm)
+	} else {
+		remote, err = io.ReadParametersFromParameterStore(ctx, *p, c.ssm)
+	}
+	if err != nil {
+		return Plan{}, err
+	}
+	return plan.New(*p, parameters, remote, deleteDelta).Protect(c.protected)
+}
+
+// secureStrings turns plain values into parameters written as SecureStrings
+func secureStrings(values map[string]string) map[string]Parameter {
+	parameters := make(map[string]Parameter, len(values))
+	for k, v := range values {
+		parameters[k] = Parameter{Value: v}
+	}
+	return parameters
+}
+
+// WithKeyID returns parameters with SecureStrings that don't name a KMS key encrypted with keyID instead of DefaultKeyID
+func WithKeyID(parameters map[string]Parameter, keyID string) map[string]Parameter {
+	output := make(map[string]Parameter, len(parameters))
+	for k, parameter := range parameters {
+		if keyID != "" && parameter.Secure() && parameter.KeyID == "" {
+			parameter.KeyID = keyID
+		}
+		output[k] = parameter
+	}
+	return output
+}
+
+func hasMetadata(parameters map[string]Parameter) bool {
+	for _, parameter := range parameters {
+		if parameter.Description != "" || parameter.KeyID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultConcurrency is how many parameters are written at once when PutOptions.Concurrency is unset
+const DefaultConcurrency = io.DefaultConcurrency
+
+// DefaultRetryBudget is how many throttled writes may be retried when PutOptions.RetryBudget is unset
+const DefaultRetryBudget = io.DefaultRetryBudget
+
+// PutOptions configures Put
+type PutOptions struct {
+	// Timeout bounds how long writing may take, one minute if unset
+	Timeout time.Duration
+	// Concurrency is how many parameters are written at once, 10 if unset
+	Concurrency int
+	// Rate is the most write requests per second, retries included. Zero means no limit.
+	Rate float64
+	// RetryBudget is how many throttled writes may be retried in total, 100 if unset
+	RetryBudget int
+}
+
+// PutResult lists the keys a Put wrote, and the unchanged keys it skipped.
+// A failed write doesn't stop the others, so when a Put fails Written lists exactly the keys written,
+// and Report says why each of the others failed.
+type PutResult struct {
+	Written []string
+	Skipped []string
+	Report  Report
+}
+
+// Put writes parameters to a path. Parameters already holding the same value are skipped,
+// so their version history is left alone.
+func (c *Client) Put(ctx context.Context, path string, parameters map[string]string, opts PutOptions) (*PutResult, error) {
+	return c.PutParameters(ctx, path, secureStrings(parameters), opts)
+}
+
+// PutParameters is Put for parameters with types, descriptions and KMS keys.
+// SecureStrings with a different KMS key than the one they're stored with are re-encrypted.
+func (c *Client) PutParameters(ctx context.Context, path string, parameters map[string]Parameter, opts PutOptions) (*PutResult, error) {
+	pl, err := c.PlanParameters(ctx, path, parameters, false)
+	if err != nil {
+		return nil, err
+	}
+	return c.apply(ctx, parameters, pl, opts)
+}
+
+// withTimeout bounds writes or deletes by the options' timeout, or defaultTimeout if it's unset
+func (opts PutOptions) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// writeOptions are the limits writes and deletes are made within
+func (opts PutOptions) writeOptions() io.WriteOptions {
+	return io.WriteOptions{
+		Concurrency: opts.Concurrency,
+		Rate:        opts.Rate,
+		RetryBudget: opts.RetryBudget,
+	}
+}
+
+// apply writes the created and updated keys of a plan
+func (c *Client) apply(ctx context.Context, parameters map[string]Parameter, pl Plan, opts PutOptions) (*PutResult, error) {
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+
+	writes := make(map[string]Parameter)
+	for _, key := range append(pl.Keys(plan.Create), pl.Keys(plan.Update)...) {
+		writes[key] = parameters[key]
+	}
+	p := util.NewParameterStorePath(pl.Path)
+	results, err := io.WriteToParameterStore(ctx, writes, *p, c.ssm, opts.writeOptions())
+	skipped := pl.Keys(plan.Unchanged)
+	r := report.New(*p, results, skipped)
+	return &PutResult{Written: r.Keys(report.Written), Skipped: skipped, Report: r}, err
+}
+
+// ApproveFunc is shown the plan for a path, and returns which of its deletes to go ahead with.
+// Returning an error stops before anything is written, and so does returning a key the plan doesn't delete,
+// like a protected key or one about to be written.
+type ApproveFunc func(pl Plan) ([]string, error)
+
+// SyncOptions configures Sync
+type SyncOptions struct {
+	PutOptions
+	// Approve is asked which keys to delete, before anything is written. A nil Approve deletes without asking.
+	Approve ApproveFunc
+}
+
+// SyncResult lists the keys a Sync wrote, skipped and deleted
+type SyncResult struct {
+	PutResult
+	Deleted []string
+}
+
+// Sync makes a path match parameters: it writes them like Put, then deletes keys at the path that are missing from parameters
+func (c *Client) Sync(ctx context.Context, path string, parameters map[string]string, opts SyncOptions) (*SyncResult, error) {
+	return c.SyncParameters(ctx, path, secureStrings(parameters), opts)
+}
+
+// SyncParameters is Sync for parameters with types, descriptions and KMS keys
+func (c *Client) SyncParameters(ctx context.Context, path string, parameters map[string]Parameter, opts SyncOptions) (*SyncResult, error) {
+	pl, err := c.PlanParameters(ctx, path, parameters, true)
+	if err != nil {
+		return nil, err
+	}
+	return c.sync(ctx, parameters, pl, opts)
+}
+
+// sync writes parameters and deletes keys as a plan from PlanParameters says, asking opts.Approve about the deletes first
+func (c *Client) sync(ctx context.Context, parameters map[string]Parameter, pl Plan, opts SyncOptions) (*SyncResult, error) {
+	deletes := pl.Keys(plan.Delete)
+	if len(deletes) > 0 && opts.Approve != nil {
+		approved, err := opts.Approve(pl)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range approved {
+			if !slices.Contains(deletes, key) {
+				return nil, fmt.Errorf("%s was approved for deletion, but the plan doesn't delete it", key)
+			}
+		}
+		deletes = approved
+	}
+	put, err := c.apply(ctx, parameters, pl, opts.PutOptions)
+	result := &SyncResult{PutResult: *put, Deleted: []string{}}
+	if err != nil || len(deletes) == 0 {
+		return result, err
+	}
+	ctx, cancel := opts.withTimeout(ctx)
+	defer cancel()
+	p := util.NewParameterStorePath(pl.Path)
+	results, err := io.DeleteFromParameterStore(ctx, deletes, *p, c.ssm, opts.writeOptions())
+	result.Report = result.Report.WithDeletes(results)
+	result.Deleted = result.Report.Keys(report.Deleted)
+	return result, err
+}
+
+// ReadFile reads a json, yaml or env file of key/value pairs.
+// An empty format picks one from the file's extension: .yaml or .yml, .env, or json for anything else.
+func ReadFile(filepath string, format string) (map[string]string, error) {
+	return io.ReadFile(filepath, format)
+}
+
+// Merge merges layers left to right, so a key in a later layer replaces the same key from earlier ones,
+// and explains which layer each key came from. Resolve references after merging, so they can point across layers.
+func Merge(layers []Layer) (map[string]string, Explanation) {
+	return merge.Merge(layers)
+}
+
+// CopyOptions configures Copy
+type CopyOptions struct {
+	PutOptions
+	// Delete removes keys at the destination that aren't copied from the source, like Sync.
+	// Only keys passing Include and Exclude are deleted, so keys the copy leaves out are left alone at the destination too.
+	Delete bool
+	// Approve is asked which keys to delete, before anything is written. A nil Approve deletes without asking.
+	Approve ApproveFunc
+	// KeyID re-encrypts every SecureString with this KMS key, instead of the key it's encrypted with at the source
+	KeyID string
+	// Include and Exclude are glob patterns, like db_*, picking which keys are copied.
+	// Without Include every key that isn't excluded is copied.
+	Include []string
+	Exclude []string
+}
+
+// CopyResult lists the keys a Copy wrote, skipped, deleted and tagged
+type CopyResult struct {
+	SyncResult
+	Tagged []string
+}
+
+// PlanCopy works out what copying parameters from a path to a path of dst would do, without writing anything.
+// dst may be c itself, or a Client for another account or region.
+func (c *Client) PlanCopy(ctx context.Context, src string, dst *Client, dstPath string, opts CopyOptions) (Plan, error) {
+	parameters, err := c.copySource(ctx, src, opts)
+	if err != nil {
+		return Plan{}, err
+	}
+	return dst.planCopy(ctx, dstPath, parameters, opts)
+}
+
+// planCopy plans writing copied parameters to a path, only deleting keys that pass the copy's include and exclude patterns
+func (c *Client) planCopy(ctx context.Context, path string, parameters map[string]Parameter, opts CopyOptions) (Plan, error) {
+	pl, err := c.PlanParameters(ctx, path, parameters, opts.Delete)
+	if err != nil {
+		return Plan{}, err
+	}
+	return pl.Narrow(opts.Include, opts.Exclude)
+}
+
+// Copy copies parameters from a path to a path of dst, keeping their types, descriptions, KMS keys and tags,
+// without them passing through a file. dst may be c itself, or a Client for another account or region,
+// where SecureStrings encrypted with a customer managed key need opts.KeyID to name a key that exists there.
+func (c *Client) Copy(ctx context.Context, src string, dst *Client, dstPath string, opts CopyOptions) (*CopyResult, error) {
+	parameters, err := c.copySource(ctx, src, opts)
+	if err != nil {
+		return nil, err
+	}
+	srcPath := util.NewParameterStorePath(src)
+	names := make([]string, 0, len(parameters))
+	for k := range parameters {
+		names = append(names, srcPath.String()+k)
+	}
+	tags, err := io.ReadTags(ctx, names, c.ssm, opts.writeOptions())
+	if err != nil {
+		return nil, err
+	}
+	// fail before writing anything, rather than after writing every parameter but their tags
+	if _, ok := dst.ssm.(TagClient); !ok && len(tags) > 0 {
+		return nil, fmt.Errorf("%w: the destination SSM client can't add tags", errors.ErrUnsupported)
+	}
+
+	var sync *SyncResult
+	if opts.Delete {
+		var pl Plan
+		pl, err = dst.planCopy(ctx, dstPath, parameters, opts)
+		if err != nil {
+			return nil, err
+		}
+		sync, err = dst.sync(ctx, parameters, pl, SyncOptions{PutOptions: opts.PutOptions, Approve: opts.Approve})
+	} else {
+		var put *PutResult
+		put, err = dst.PutParameters(ctx, dstPath, parameters, opts.PutOptions)
+		if put != nil {
+			sync = &SyncResult{PutResult: *put, Deleted: []string{}}
+		}
+	}
+	if sync == nil {
+		return nil, err
+	}
+	result := &CopyResult{SyncResult: *sync, Tagged: []string{}}
+	if err != nil {
+		return result, err
+	}
+
+	// tags can't be set along with overwriting a parameter, so they're added to every copied key once it's written
+	p := util.NewParameterStorePath(dstPath)
+	dstTags := make(map[string][]types.Tag)
+	for _, key := range append(result.Written, result.Skipped...) {
+		if t, ok := tags[srcPath.String()+key]; ok {
+			dstTags[p.String()+key] = t
+		}
+	}
+	tagged, err := io.WriteTags(ctx, dstTags, dst.ssm, opts.writeOptions())
+	for _, name := range tagged {
+		result.Tagged = append(result.Tagged, strings.TrimPrefix(name, p.String()))
+	}
+	sort.Strings(result.Tagged)
+	return result, err
+}
+
+// copySource reads the parameters at a path that Copy copies
+func (c *Client) copySource(ctx context.Context, src string, opts CopyOptions) (map[string]Parameter, error) {
+	all, err := c.GetParameters(ctx, src, GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	keys, err := util.FilterKeys(maps.Keys(all), opts.Include, opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	parameters := make(map[string]Parameter, len(keys))
+	for _, k := range keys {
+		parameter := all[k]
+		if opts.KeyID != "" && parameter.Secure() {
+			parameter.KeyID = opts.KeyID
+		}
+		parameters[k] = parameter
+	}
+	return parameters, nil
+}
+
+// History reads every version of each parameter at a path, oldest first.
+// Parameter store deletes the history of a parameter along with it, so deleted parameters have none.
+func (c *Client) History(ctx context.Context, path string) (map[string][]Version, error) {
+	return c.history(ctx, path, PutOptions{})
+}
+
+// history reads every version of each parameter at a path, within the rate and retry budget of opts
+func (c *Client) history(ctx context.Context, path string, opts PutOptions) (map[string][]Version, error) {
+	p := util.NewParameterStorePath(path)
+	return io.ReadPathHistory(ctx, *p, c.ssm, opts.writeOptions())
+}
+
+// RollbackOptions configures Rollback
+type RollbackOptions struct {
+	PutOptions
+	// Delete removes keys that have no version at the target, like keys created since, as Sync would
+	Delete bool
+	// Approve is asked which keys to delete, before anything is written. A nil Approve deletes without asking.
+	Approve ApproveFunc
+}
+
+// RollbackResult lists the keys a Rollback wrote, skipped and deleted, and the keys that had no version at the target
+type RollbackResult struct {
+	SyncResult
+	Missing []string
+}
+
+// PlanRollback works out what rolling a path back would do, without writing anything
+func (c *Client) PlanRollback(ctx context.Context, path string, to string, opts RollbackOptions) (Plan, error) {
+	parameters, _, err := c.rollbackTarget(ctx, path, to, opts.PutOptions)
+	if err != nil {
+		return Plan{}, err
+	}
+	return c.PlanParameters(ctx, path, parameters, opts.Delete)
+}
+
+// Rollback writes every key at a path back to the value, type, description and KMS key it had at a point in its history.
+// to is either a timestamp like 2026-10-17T12:00:00Z or 2026-10-17 12:00 (in local time), or a version label.
+// Keys that already match are skipped, and each key rolled back gets a new version.
+// Deleted keys have no history left in parameter store, so they can't be recreated.
+func (c *Client) Rollback(ctx context.Context, path string, to string, opts RollbackOptions) (*RollbackResult, error) {
+	parameters, missing, err := c.rollbackTarget(ctx, path, to, opts.PutOptions)
+	if err != nil {
+		return nil, err
+	}
+	sync, err := c.putOrSync(ctx, path, parameters, opts.PutOptions, opts.Delete, opts.Approve)
+	if sync == nil {
+		return nil, err
+	}
+	return &RollbackResult{SyncResult: *sync, Missing: missing}, err
+}
+
+// putOrSync syncs parameters to a path when deleteDelta is set, and only puts them otherwise
+func (c *Client) putOrSync(ctx context.Context, path string, parameters map[string]Parameter, opts PutOptions, deleteDelta bool, approve ApproveFunc) (*SyncResult, error) {
+	if deleteDelta {
+		return c.SyncParameters(ctx, path, parameters, SyncOptions{PutOptions: opts, Approve: approve})
+	}
+	put, err := c.PutParameters(ctx, path, parameters, opts)
+	if put == nil {
+		return nil, err
+	}
+	return &SyncResult{PutResult: *put, Deleted: []string{}}, err
+}
+
+// rollbackTarget returns each key at a path as it was at a point in its history, along with the keys that had no version then.
+// The history is read within the same rate and retry budget as the writes of opts.
+func (c *Client) rollbackTarget(ctx context.Context, path string, to string, opts PutOptions) (map[string]Parameter, []string, error) {
+	target := history.ParseTarget(to)
+	versions, err := c.history(ctx, path, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	parameters := make(map[string]Parameter, len(versions))
+	missing := make([]string, 0)
+	for k, v := range versions {
+		selected, ok := history.Select(v, target)
+		if !ok {
+			missing = append(missing, k)
+			continue
+		}
+		parameters[k] = selected.Parameter
+	}
+	sort.Strings(missing)
+	return parameters, missing, nil
+}
+
+// Snapshot takes the state of every parameter at a path, with its type, description and KMS key
+func (c *Client) Snapshot(ctx context.Context, path string) (*Snapshot, error) {
+	parameters, err := c.GetParameters(ctx, path, GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Path: path, Taken: time.Now(), Parameters: parameters}, nil
+}
+
+// SaveSnapshot encrypts a snapshot into a new file in dir, with the key held in keyFile, and returns the file's name.
+// keyFile is created with a new random key if it doesn't exist; snapshots can't be restored without it.
+// keyFile can't be inside dir, so copying or sharing the snapshots doesn't hand out the key to read them with.
+func SaveSnapshot(s *Snapshot, dir string, keyFile string) (string, error) {
+	if inside(dir, keyFile) {
+		return "", fmt.Errorf("snapshot key %s can't be kept in the snapshot directory %s", keyFile, dir)
+	}
+	key, err := snapshot.Key(keyFile)
+	if err != nil {
+		return "", err
+	}
+	return s.Save(dir, key)
+}
+
+// PruneSnapshots removes all but the newest keep snapshots of a path from dir, and returns the names of the files it removed
+func PruneSnapshots(dir string, path string, keep int) ([]string, error) {
+	return snapshot.Prune(dir, path, keep)
+}
+
+// inside reports whether filename is in dir or one of its subdirectories
+func inside(dir string, filename string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absFile, err := filepath.Abs(filename)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absFile)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// LoadSnapshot decrypts a snapshot file saved by SaveSnapshot, with the key held in keyFile
+func LoadSnapshot(filename string, keyFile string) (*Snapshot, error) {
+	key, err := snapshot.Key(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	s, err := snapshot.Load(filename, key)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// RestoreOptions configures Restore
+type RestoreOptions struct {
+	PutOptions
+	// Delete removes keys that weren't in the snapshot, like keys created since, as Sync would
+	Delete bool
+	// Approve is asked which keys to delete, before anything is written. A nil Approve deletes without asking.
+	Approve ApproveFunc
+}
+
+// PlanRestore works out what restoring a snapshot would do, without writing anything
+func (c *Client) PlanRestore(ctx context.Context, s *Snapshot, opts RestoreOptions) (Plan, error) {
+	return c.PlanParameters(ctx, s.Path, s.Parameters, opts.Delete)
+}
+
+// Restore writes every key in a snapshot back to its path, with the value, type, description and KMS key it had.
+// Unlike Rollback, this recreates keys deleted since the snapshot was taken. Keys that already match are skipped.
+func (c *Client) Restore(ctx context.Context, s *Snapshot, opts RestoreOptions) (*SyncResult, error) {
+	return c.putOrSync(ctx, s.Path, s.Parameters, opts.PutOptions, opts.Delete, opts.Approve)
+}
+
+// ReadParameterFile reads a json, yaml or env file of parameters. In json and yaml files, each key holds
+// either a plain value, or an object like {"value": "...", "type": "String", "description": "...", "kms_key_id": "..."}.
+// An empty format picks one from the file's extension, like ReadFile.
+func ReadParameterFile(filepath string, format string) (map[string]Parameter, error) {
+	return io.ReadParameterFile(filepath, format)
+}
+
+// Formatter serializes flat key/value parameters in a single format, reporting keys it can't hold with an *InvalidKeyError
+type Formatter = format.Formatter
+
+// FormatterFunc adapts a function to a Formatter
+type FormatterFunc = format.Func
+
+// FormatOptions holds the settings only some formats use, like the name of a Kubernetes manifest
+type FormatOptions = format.Options
+
+// ManifestOptions are the name, namespace and labels of a Kubernetes manifest
+type ManifestOptions = k8s.Options
+
+// ErrUnknownFormat is returned for formats that aren't registered
+var ErrUnknownFormat = format.ErrUnknownFormat
+
+// RegisterFormat adds a format that Format, the get command's --format and the load command's --format can use.
+// It panics if the name or an alias is already taken.
+func RegisterFormat(name string, description string, formatter Formatter, aliases ...string) {
+	format.Register(name, description, formatter, aliases...)
+}
+
+// Formats lists every registered format, without aliases
+func Formats() []string {
+	return format.Names()
+}
+
+// FormatHelp describes every registered format, one per line
+func FormatHelp() string {
+	return format.Help()
+}
+
+// Format serializes parameters in a registered format: json, yaml, env, a script setting them as environment
+// variables in bash, fish, powershell, nushell or csh, or a k8s-secret or k8s-configmap manifest. See Formats.
+func Format(parameters map[string]string, name string) (string, error) {
+	return format.Format(parameters, name, FormatOptions{})
+}
+
+// FormatWith is Format for formats that take options, like the name and namespace of a k8s-secret
+func FormatWith(parameters map[string]string, name string, opts FormatOptions) (string, error) {
+	return format.Format(parameters, name, opts)
+}
+
+// Render executes a text/template with parameters as its data, like {{ .db_host }}, failing on references to missing keys.
+// Templates can call default, required, base64, jsonEscape and shellQuote besides text/template's own functions.
+func Render(name string, text string, parameters map[string]string) (string, error) {
+	return render.Render(name, text, parameters)
+}
+
+// FormatParameters serializes parameters with their types, descriptions and KMS keys as json or yaml (or yml),
+// in the extended schema ReadParameterFile reads
+func FormatParameters(parameters map[string]Parameter, name string) (string, error) {
+	switch name {
+	case "json":
+		return json.MarshalParameters(parameters)
+	case "yaml", "yml":
+		serialized, err := yaml.Marshal(parameters)
+		return string(serialized), err
+	}
+	return "", fmt.Errorf("%w: %s can't hold parameters with metadata (yaml, json allowed)", ErrUnknownFormat, name)
+}
@@ -0,0 +1,716 @@
+package gorson
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// fakeSSM is an in-memory parameter store keyed by full parameter name
+type fakeSSM struct {
+	mu           sync.Mutex
+	parameters   map[string]types.Parameter
+	descriptions map[string]string
+	keyIDs       map[string]string
+	tags         map[string][]types.Tag
+	history      map[string][]types.ParameterHistory
+	// clock is the time of the last write, moved on a minute by every write
+	clock time.Time
+	puts  []string
+}
+
+func newFakeSSM(values map[string]string) *fakeSSM {
+	f := &fakeSSM{parameters: make(map[string]types.Parameter), descriptions: make(map[string]string), keyIDs: make(map[string]string), tags: make(map[string][]types.Tag),
+		history: make(map[string][]types.ParameterHistory), clock: time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)}
+	for name, value := range values {
+		f.parameters[name] = types.Parameter{
+			Name:  aws.String(name),
+			Value: aws.String(value),
+			Type:  types.ParameterTypeSecureString,
+		}
+		f.record(f.parameters[name])
+	}
+	return f
+}
+
+// record adds a parameter as its next version to its history
+func (f *fakeSSM) record(parameter types.Parameter) {
+	f.clock = f.clock.Add(time.Minute)
+	modified := f.clock
+	f.history[*parameter.Name] = append(f.history[*parameter.Name], types.ParameterHistory{
+		Name:             parameter.Name,
+		Value:            parameter.Value,
+		Type:             parameter.Type,
+		Version:          int64(len(f.history[*parameter.Name]) + 1),
+		LastModifiedDate: &modified,
+		LastModifiedUser: aws.String("tester"),
+	})
+}
+
+func (f *fakeSSM) GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput, opts ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	output := ssm.GetParametersByPathOutput{}
+	for name, parameter := range f.parameters {
+		if !strings.HasPrefix(name, *input.Path) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, *input.Path)
+		if strings.Contains(rest, "/") && !aws.ToBool(input.Recursive) {
+			continue
+		}
+		output.Parameters = append(output.Parameters, parameter)
+	}
+	return &output, nil
+}
+
+func (f *fakeSSM) PutParameter(ctx context.Context, input *ssm.PutParameterInput, opts ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.parameters[*input.Name] = types.Parameter{Name: input.Name, Value: input.Value, Type: input.Type}
+	f.record(f.parameters[*input.Name])
+	if input.Description != nil {
+		f.descriptions[*input.Name] = *input.Description
+	}
+	f.keyIDs[*input.Name] = aws.ToString(input.KeyId)
+	f.puts = append(f.puts, *input.Name)
+	return &ssm.PutParameterOutput{}, nil
+}
+
+func (f *fakeSSM) DeleteParameters(ctx context.Context, input *ssm.DeleteParametersInput, opts ...func(*ssm.Options)) (*ssm.DeleteParametersOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, name := range input.Names {
+		delete(f.parameters, name)
+		delete(f.history, name)
+	}
+	return &ssm.DeleteParametersOutput{DeletedParameters: input.Names}, nil
+}
+
+func (f *fakeSSM) DescribeParameters(ctx context.Context, input *ssm.DescribeParametersInput, opts ...func(*ssm.Options)) (*ssm.DescribeParametersOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	filter := input.ParameterFilters[0]
+	path := strings.TrimSuffix(filter.Values[0], "/") + "/"
+	output := ssm.DescribeParametersOutput{}
+	for name, parameter := range f.parameters {
+		if !strings.HasPrefix(name, path) {
+			continue
+		}
+		if strings.Contains(strings.TrimPrefix(name, path), "/") && aws.ToString(filter.Option) != "Recursive" {
+			continue
+		}
+		output.Parameters = append(output.Parameters, types.ParameterMetadata{
+			Name:        parameter.Name,
+			Type:        parameter.Type,
+			Description: aws.String(f.descriptions[name]),
+			KeyId:       aws.String(f.keyIDs[name]),
+		})
+	}
+	return &output, nil
+}
+
+func (f *fakeSSM) ListTagsForResource(ctx context.Context, input *ssm.ListTagsForResourceInput, opts ...func(*ssm.Options)) (*ssm.ListTagsForResourceOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &ssm.ListTagsForResourceOutput{TagList: f.tags[*input.ResourceId]}, nil
+}
+
+func (f *fakeSSM) AddTagsToResource(ctx context.Context, input *ssm.AddTagsToResourceInput, opts ...func(*ssm.Options)) (*ssm.AddTagsToResourceOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tags[*input.ResourceId] = append(f.tags[*input.ResourceId], input.Tags...)
+	return &ssm.AddTagsToResourceOutput{}, nil
+}
+
+func (f *fakeSSM) GetParameterHistory(ctx context.Context, input *ssm.GetParameterHistoryInput, opts ...func(*ssm.Options)) (*ssm.GetParameterHistoryOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &ssm.GetParameterHistoryOutput{Parameters: f.history[*input.Name]}, nil
+}
+
+func (f *fakeSSM) values() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := make(map[string]string)
+	for name, parameter := range f.parameters {
+		values[name] = *parameter.Value
+	}
+	return values
+}
+
+func TestGet(t *testing.T) {
+	fake := newFakeSSM(map[string]string{
+		"/app/name":    "gorson",
+		"/app/db/host": "localhost",
+	})
+	client, err := New(context.Background(), fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat, err := client.Get(context.Background(), "/app", GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"name": "gorson"}; !reflect.DeepEqual(expected, flat) {
+		t.Errorf("expected %v, got %v", expected, flat)
+	}
+
+	nested, err := client.Get(context.Background(), "/app", GetOptions{Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"name": "gorson", "db/host": "localhost"}; !reflect.DeepEqual(expected, nested) {
+		t.Errorf("expected %v, got %v", expected, nested)
+	}
+}
+
+func TestGetResolve(t *testing.T) {
+	fake := newFakeSSM(map[string]string{
+		"/shared/db/host": "db.internal",
+		"/app/host":       "{{ssm:/shared/db/host}}",
+		"/app/url":        "postgres://${host}/",
+	})
+	client, err := New(context.Background(), fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := client.Get(context.Background(), "/app", GetOptions{Resolve: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"host": "db.internal", "url": "postgres://db.internal/"}; !reflect.DeepEqual(expected, resolved) {
+		t.Errorf("expected %v, got %v", expected, resolved)
+	}
+
+	raw, err := client.Get(context.Background(), "/app", GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw["url"] != "postgres://${host}/" {
+		t.Errorf("expected the reference to be kept without Resolve, got %s", raw["url"])
+	}
+
+	_, err = client.Resolve(context.Background(), map[string]string{"url": "${missing}"}, "")
+	if !errors.Is(err, ErrUnresolvable) {
+		t.Errorf("expected ErrUnresolvable, got %v", err)
+	}
+}
+
+// what get writes as json, yaml or env, references and escapes included, put writes back unchanged
+func TestGetPutRoundTrip(t *testing.T) {
+	values := map[string]string{"script": "cd $${HOME}", "url": "postgres://${host}/", "host": "{{ssm:/shared/db/host}}"}
+	for _, name := range []string{"json", "yaml", "env"} {
+		fake := newFakeSSM(map[string]string{"/app/script": values["script"], "/app/url": values["url"], "/app/host": values["host"]})
+		client, _ := New(context.Background(), fake)
+		parameters, err := client.Get(context.Background(), "/app/", GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		output, err := Format(parameters, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(t.TempDir(), "parameters."+name)
+		if err := os.WriteFile(filename, []byte(output), 0600); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadFile(filename, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Put(context.Background(), "/copy/", read, PutOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		copied, err := client.Get(context.Background(), "/copy/", GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(values, copied) {
+			t.Errorf("%s: expected %v, got %v", name, values, copied)
+		}
+	}
+}
+
+func TestPut(t *testing.T) {
+	fake := newFakeSSM(map[string]string{
+		"/app/same":    "value",
+		"/app/changed": "old",
+		"/app/extra":   "value",
+	})
+	client, _ := New(context.Background(), fake)
+
+	result, err := client.Put(context.Background(), "/app/", map[string]string{
+		"same":    "value",
+		"changed": "new",
+		"added":   "value",
+	}, PutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(result.Written)
+	if expected := []string{"added", "changed"}; !reflect.DeepEqual(expected, result.Written) {
+		t.Errorf("expected written %v, got %v", expected, result.Written)
+	}
+	if expected := []string{"same"}; !reflect.DeepEqual(expected, result.Skipped) {
+		t.Errorf("expected skipped %v, got %v", expected, result.Skipped)
+	}
+	if written := result.Report.Keys(Written); !reflect.DeepEqual([]string{"added", "changed"}, written) {
+		t.Errorf("expected the report to list added and changed as written, got %v", written)
+	}
+	if len(fake.puts) != 2 {
+		t.Errorf("expected 2 PutParameter calls, got %v", fake.puts)
+	}
+	if _, ok := fake.values()["/app/extra"]; !ok {
+		t.Error("put should not delete parameters missing from the file")
+	}
+}
+
+func TestSync(t *testing.T) {
+	fake := newFakeSSM(map[string]string{
+		"/app/same":  "value",
+		"/app/extra": "value",
+	})
+	client, _ := New(context.Background(), fake)
+
+	declined, err := client.Sync(context.Background(), "/app/", map[string]string{"same": "value"}, SyncOptions{
+		Approve: func(pl Plan) ([]string, error) {
+			return []string{}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(declined.Deleted) != 0 || len(fake.values()) != 2 {
+		t.Errorf("expected nothing deleted without approval, got %v", declined.Deleted)
+	}
+
+	var approvedKeys []string
+	result, err := client.Sync(context.Background(), "/app/", map[string]string{"same": "value"}, SyncOptions{
+		Approve: func(pl Plan) ([]string, error) {
+			approvedKeys = pl.Keys(Delete)
+			return approvedKeys, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"extra"}; !reflect.DeepEqual(expected, approvedKeys) || !reflect.DeepEqual(expected, result.Deleted) {
+		t.Errorf("expected %v approved and deleted, got %v and %v", expected, approvedKeys, result.Deleted)
+	}
+	if expected := map[string]string{"/app/same": "value"}; !reflect.DeepEqual(expected, fake.values()) {
+		t.Errorf("expected %v, got %v", expected, fake.values())
+	}
+}
+
+func TestSyncDeletesEveryBatch(t *testing.T) {
+	values := map[string]string{"/app/kept": "value"}
+	for i := 0; i < 22; i++ {
+		values[fmt.Sprintf("/app/extra%02d", i)] = "value"
+	}
+	fake := newFakeSSM(values)
+	client, _ := New(context.Background(), fake)
+
+	result, err := client.Sync(context.Background(), "/app/", map[string]string{"kept": "value"}, SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Deleted) != 22 {
+		t.Errorf("expected 22 parameters deleted, got %v", result.Deleted)
+	}
+	if expected := map[string]string{"/app/kept": "value"}; !reflect.DeepEqual(expected, fake.values()) {
+		t.Errorf("expected %v, got %v", expected, fake.values())
+	}
+
+	unchanged, err := client.Sync(context.Background(), "/app/", map[string]string{"kept": "value"}, SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unchanged.Deleted) != 0 {
+		t.Errorf("expected nothing left to delete, got %v", unchanged.Deleted)
+	}
+}
+
+func TestSyncApprove(t *testing.T) {
+	fake := newFakeSSM(map[string]string{"/app/one": "value", "/app/two": "value"})
+	client, _ := New(context.Background(), fake)
+
+	refused, err := client.Sync(context.Background(), "/app/", map[string]string{"new": "value"}, SyncOptions{
+		Approve: func(pl Plan) ([]string, error) {
+			return nil, ErrNotTerminal
+		},
+	})
+	if !errors.Is(err, ErrNotTerminal) || refused != nil {
+		t.Errorf("expected %v and no result, got %v and %v", ErrNotTerminal, err, refused)
+	}
+	if len(fake.puts) != 0 {
+		t.Errorf("expected nothing written when approval fails, got %v", fake.puts)
+	}
+
+	result, err := client.Sync(context.Background(), "/app/", map[string]string{"new": "value"}, SyncOptions{
+		Approve: func(pl Plan) ([]string, error) {
+			return []string{"two"}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"two"}; !reflect.DeepEqual(expected, result.Deleted) {
+		t.Errorf("expected only %v deleted, got %v", expected, result.Deleted)
+	}
+	if expected := []string{"two"}; !reflect.DeepEqual(expected, result.Report.Keys(Deleted)) {
+		t.Errorf("expected the report to list %v as deleted, got %v", expected, result.Report.Keys(Deleted))
+	}
+	if expected := map[string]string{"/app/one": "value", "/app/new": "value"}; !reflect.DeepEqual(expected, fake.values()) {
+		t.Errorf("expected %v, got %v", expected, fake.values())
+	}
+}
+
+func TestSyncApproveOnlyPlannedDeletes(t *testing.T) {
+	fake := newFakeSSM(map[string]string{"/app/old": "value", "/app/terraform-host": "value"})
+	client, _ := New(context.Background(), fake)
+	if err := client.Protect("terraform-*"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"terraform-host", "new"} {
+		_, err := client.Sync(context.Background(), "/app/", map[string]string{"new": "value"}, SyncOptions{
+			Approve: func(pl Plan) ([]string, error) {
+				return []string{"old", key}, nil
+			},
+		})
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("expected an error naming %s, got %v", key, err)
+		}
+	}
+	if len(fake.puts) != 0 {
+		t.Errorf("expected nothing written, got %v", fake.puts)
+	}
+	if expected := map[string]string{"/app/old": "value", "/app/terraform-host": "value"}; !reflect.DeepEqual(expected, fake.values()) {
+		t.Errorf("expected nothing deleted, got %v", fake.values())
+	}
+}
+
+func TestProtect(t *testing.T) {
+	fake := newFakeSSM(map[string]string{"/app/same": "value", "/app/extra": "value", "/app/terraform-host": "value"})
+	client, _ := New(context.Background(), fake)
+	if err := client.Protect("["); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+	if err := client.Protect("terraform-*"); err != nil {
+		t.Fatal(err)
+	}
+
+	pl, err := client.Plan(context.Background(), "/app/", map[string]string{"same": "value"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"terraform-host"}; !reflect.DeepEqual(expected, pl.Keys(Protected)) {
+		t.Errorf("expected %v protected, got %v", expected, pl.Keys(Protected))
+	}
+	result, err := client.Sync(context.Background(), "/app/", map[string]string{"same": "value"}, SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"extra"}; !reflect.DeepEqual(expected, result.Deleted) {
+		t.Errorf("expected only %v deleted, got %v", expected, result.Deleted)
+	}
+	if expected := map[string]string{"/app/same": "value", "/app/terraform-host": "value"}; !reflect.DeepEqual(expected, fake.values()) {
+		t.Errorf("expected %v, got %v", expected, fake.values())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	client, _ := New(context.Background(), newFakeSSM(map[string]string{"/app/same": "value"}))
+	changes, err := client.Diff(context.Background(), "/app/", map[string]string{"same": "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if HasDrift(changes) {
+		t.Errorf("expected no drift, got %v", changes)
+	}
+}
+
+func TestPutParameters(t *testing.T) {
+	fake := newFakeSSM(map[string]string{"/app/plain": "value"})
+	client, _ := New(context.Background(), fake)
+
+	parameters := map[string]Parameter{
+		"plain":  {Value: "value", Type: types.ParameterTypeString, Description: "a plain string"},
+		"list":   {Value: "a,b", Type: types.ParameterTypeStringList},
+		"secret": {Value: "hunter2"},
+	}
+	result, err := client.PutParameters(context.Background(), "/app/", parameters, PutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"list", "plain", "secret"}; !reflect.DeepEqual(expected, result.Written) {
+		t.Errorf("expected written %v, got %v", expected, result.Written)
+	}
+
+	read, err := client.GetParameters(context.Background(), "/app/", GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parameters["secret"] = Parameter{Value: "hunter2", Type: types.ParameterTypeSecureString, KeyID: DefaultKeyID}
+	if !reflect.DeepEqual(parameters, read) {
+		t.Errorf("expected %v, got %v", parameters, read)
+	}
+
+	// writing back what was read changes nothing
+	again, err := client.PutParameters(context.Background(), "/app/", read, PutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Written) != 0 {
+		t.Errorf("expected a round trip to write nothing, got %v", again.Written)
+	}
+}
+
+func TestWithKeyID(t *testing.T) {
+	fake := newFakeSSM(nil)
+	client, _ := New(context.Background(), fake)
+
+	parameters := map[string]Parameter{
+		"plain":  {Value: "value", Type: types.ParameterTypeString},
+		"secret": {Value: "hunter2"},
+		"named":  {Value: "hunter3", KeyID: "alias/other"},
+	}
+	if _, err := client.PutParameters(context.Background(), "/app/", WithKeyID(parameters, "alias/app"), PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"/app/plain": "", "/app/secret": "alias/app", "/app/named": "alias/other"}
+	if !reflect.DeepEqual(expected, fake.keyIDs) {
+		t.Errorf("expected KMS keys %v, got %v", expected, fake.keyIDs)
+	}
+
+	// moving to another key re-encrypts parameters whose values are unchanged
+	result, err := client.PutParameters(context.Background(), "/app/", WithKeyID(parameters, "alias/rotated"), PutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"secret"}; !reflect.DeepEqual(expected, result.Written) {
+		t.Errorf("expected %v re-encrypted, got %v", expected, result.Written)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	src := newFakeSSM(map[string]string{
+		"/staging/app/db_host":     "staging-db",
+		"/staging/app/db_password": "hunter2",
+		"/staging/app/name":        "app",
+	})
+	src.parameters["/staging/app/name"] = types.Parameter{Name: aws.String("/staging/app/name"), Value: aws.String("app"), Type: types.ParameterTypeString}
+	src.descriptions["/staging/app/name"] = "the app name"
+	src.tags["/staging/app/db_host"] = []types.Tag{{Key: aws.String("team"), Value: aws.String("platform")}}
+	dst := newFakeSSM(map[string]string{"/prod/app/stale": "value"})
+	srcClient, _ := New(context.Background(), src)
+	dstClient, _ := New(context.Background(), dst)
+
+	result, err := srcClient.Copy(context.Background(), "/staging/app/", dstClient, "/prod/app/", CopyOptions{
+		Delete:  true,
+		KeyID:   "alias/prod",
+		Exclude: []string{"*password"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"db_host", "name"}; !reflect.DeepEqual(expected, result.Written) {
+		t.Errorf("expected written %v, got %v", expected, result.Written)
+	}
+	if expected := []string{"stale"}; !reflect.DeepEqual(expected, result.Deleted) {
+		t.Errorf("expected deleted %v, got %v", expected, result.Deleted)
+	}
+	if expected := []string{"db_host"}; !reflect.DeepEqual(expected, result.Tagged) {
+		t.Errorf("expected tagged %v, got %v", expected, result.Tagged)
+	}
+
+	copied, err := dstClient.GetParameters(context.Background(), "/prod/app/", GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]Parameter{
+		"db_host": {Value: "staging-db", Type: types.ParameterTypeSecureString, KeyID: "alias/prod"},
+		"name":    {Value: "app", Type: types.ParameterTypeString, Description: "the app name"},
+	}
+	if !reflect.DeepEqual(expected, copied) {
+		t.Errorf("expected %v, got %v", expected, copied)
+	}
+	if tags := dst.tags["/prod/app/db_host"]; len(tags) != 1 || *tags[0].Key != "team" {
+		t.Errorf("expected the team tag copied, got %v", tags)
+	}
+}
+
+func TestCopyDeletesOnlyFilteredKeys(t *testing.T) {
+	src := newFakeSSM(map[string]string{"/staging/app/db_host": "staging-db", "/staging/app/db_password": "hunter2"})
+	dst := newFakeSSM(map[string]string{
+		"/prod/app/db_password": "prod-secret",
+		"/prod/app/db_stale":    "value",
+		"/prod/app/name":        "app",
+	})
+	srcClient, _ := New(context.Background(), src)
+	dstClient, _ := New(context.Background(), dst)
+	opts := CopyOptions{Delete: true, Include: []string{"db_*"}, Exclude: []string{"*_password"}}
+
+	pl, err := srcClient.PlanCopy(context.Background(), "/staging/app/", dstClient, "/prod/app/", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"db_stale"}; !reflect.DeepEqual(expected, pl.Keys(Delete)) {
+		t.Errorf("expected planned deletes %v, got %v", expected, pl.Keys(Delete))
+	}
+	result, err := srcClient.Copy(context.Background(), "/staging/app/", dstClient, "/prod/app/", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"db_stale"}; !reflect.DeepEqual(expected, result.Deleted) {
+		t.Errorf("expected deleted %v, got %v", expected, result.Deleted)
+	}
+	expected := map[string]string{"/prod/app/db_host": "staging-db", "/prod/app/db_password": "prod-secret", "/prod/app/name": "app"}
+	if !reflect.DeepEqual(expected, dst.values()) {
+		t.Errorf("expected the excluded and not included keys kept, got %v", dst.values())
+	}
+}
+
+func TestCopyWithoutTagClient(t *testing.T) {
+	src := newFakeSSM(map[string]string{"/staging/app/db_host": "staging-db"})
+	src.tags["/staging/app/db_host"] = []types.Tag{{Key: aws.String("team"), Value: aws.String("platform")}}
+	dst := newFakeSSM(map[string]string{})
+	srcClient, _ := New(context.Background(), src)
+	// a client with the DescribeClient methods, but not the TagClient ones
+	dstClient, _ := New(context.Background(), struct {
+		SSMClient
+		DescribeClient
+	}{dst, dst})
+
+	_, err := srcClient.Copy(context.Background(), "/staging/app/", dstClient, "/prod/app/", CopyOptions{})
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("expected %v, got %v", errors.ErrUnsupported, err)
+	}
+	if len(dst.puts) != 0 {
+		t.Errorf("expected nothing written, got %v", dst.puts)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	fake := newFakeSSM(map[string]string{"/app/alpha": "one", "/app/beta": "one"})
+	client, _ := New(context.Background(), fake)
+	before := fake.clock
+
+	if _, err := client.Put(context.Background(), "/app/", map[string]string{"alpha": "two", "beta": "one", "gamma": "new"}, PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	history, err := client.History(context.Background(), "/app/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history["alpha"]) != 2 || len(history["beta"]) != 1 {
+		t.Fatalf("expected two versions of alpha and one of beta, got %v", history)
+	}
+
+	result, err := client.Rollback(context.Background(), "/app/", before.Format(time.RFC3339), RollbackOptions{Delete: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"alpha"}; !reflect.DeepEqual(expected, result.Written) {
+		t.Errorf("expected %v rolled back, got %v", expected, result.Written)
+	}
+	if expected := []string{"gamma"}; !reflect.DeepEqual(expected, result.Missing) || !reflect.DeepEqual(expected, result.Deleted) {
+		t.Errorf("expected %v missing and deleted, got %v and %v", expected, result.Missing, result.Deleted)
+	}
+	if expected := map[string]string{"/app/alpha": "one", "/app/beta": "one"}; !reflect.DeepEqual(expected, fake.values()) {
+		t.Errorf("expected %v, got %v", expected, fake.values())
+	}
+}
+
+// throttledHistory is a fakeSSM that throttles the first few requests for parameter history
+type throttledHistory struct {
+	*fakeSSM
+	throttles int
+}
+
+func (f *throttledHistory) GetParameterHistory(ctx context.Context, input *ssm.GetParameterHistoryInput, opts ...func(*ssm.Options)) (*ssm.GetParameterHistoryOutput, error) {
+	f.mu.Lock()
+	if f.throttles > 0 {
+		f.throttles--
+		f.mu.Unlock()
+		return nil, &types.ThrottlingException{Message: aws.String("rate exceeded")}
+	}
+	f.mu.Unlock()
+	return f.fakeSSM.GetParameterHistory(ctx, input, opts...)
+}
+
+func TestRollbackReadsHistoryWithinRetryBudget(t *testing.T) {
+	tests := []struct {
+		name        string
+		retryBudget int
+		expected    error
+	}{
+		{name: "budget spent", retryBudget: 1, expected: ErrThrottled},
+		{name: "budget left", retryBudget: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &throttledHistory{fakeSSM: newFakeSSM(map[string]string{"/app/alpha": "one"}), throttles: 2}
+			client, _ := New(context.Background(), fake)
+			_, err := client.Rollback(context.Background(), "/app/", "2026-10-12T09:01:00Z", RollbackOptions{PutOptions: PutOptions{RetryBudget: tt.retryBudget}})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	fake := newFakeSSM(map[string]string{"/app/alpha": "one", "/app/beta": "one"})
+	client, _ := New(context.Background(), fake)
+	dir := t.TempDir()
+	keyFile := filepath.Join(t.TempDir(), "snapshot.key")
+
+	s, err := client.Snapshot(context.Background(), "/app/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SaveSnapshot(s, dir, filepath.Join(dir, "snapshot.key")); err == nil {
+		t.Error("expected an error saving a snapshot with its key in the snapshot directory")
+	}
+	filename, err := SaveSnapshot(s, dir, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Sync(context.Background(), "/app/", map[string]string{"alpha": "two", "gamma": "new"}, SyncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSnapshot(filename, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.Restore(context.Background(), loaded, RestoreOptions{Delete: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"alpha", "beta"}; !reflect.DeepEqual(expected, result.Written) {
+		t.Errorf("expected %v restored, got %v", expected, result.Written)
+	}
+	if expected := []string{"gamma"}; !reflect.DeepEqual(expected, result.Deleted) {
+		t.Errorf("expected %v deleted, got %v", expected, result.Deleted)
+	}
+	if expected := map[string]string{"/app/alpha": "one", "/app/beta": "one"}; !reflect.DeepEqual(expected, fake.values()) {
+		t.Errorf("expected %v, got %v", expected, fake.values())
+	}
+}
@@ -6,4 +6,5 @@ cd "$( dirname "${BASH_SOURCE[0]}" )/.."
 
 gofmt -w ./cmd
 gofmt -w ./internal
+gofmt -w ./pkg
 gofmt -w ./main.go